@@ -2,6 +2,8 @@ package ast
 
 import (
 	"fmt"
+	"math/big"
+	"pcl/src/frontend/source"
 	"strings"
 )
 
@@ -14,23 +16,63 @@ const (
 	BodyNodeType
 	VarDeclNodeType
 	AssignmentNodeType
+	ParallelAssignmentNodeType
 	FunctionCallNodeType
+	MethodCallNodeType
 	ReturnNodeType
+	IfNodeType
+	WhileNodeType
+	ForNodeType
+	ForInNodeType
+	DeleteNodeType
+	BreakNodeType
+	ContinueNodeType
+	TryNodeType
+	ThrowNodeType
+	StructDeclNodeType
+	ClassDeclNodeType
+	EnumDeclNodeType
 	BinaryOpNodeType
 	UnaryOpNodeType
+	TernaryNodeType
+	MatchNodeType
 	IntLiteralNodeType
 	FloatLiteralNodeType
 	StringLiteralNodeType
+	InterpolationNodeType
 	BooleanLiteralNodeType
 	FunctionLiteralNodeType
+	ArrayLiteralNodeType
+	MapLiteralNodeType
+	IndexNodeType
+	SliceNodeType
+	MemberNodeType
+	OptionalChainNodeType
 	IdentifierNodeType
+	ThisNodeType
+	SuperNodeType
+	WildcardPatternNodeType
+	BindingPatternNodeType
+	LiteralPatternNodeType
+	RangePatternNodeType
+	ArrayPatternNodeType
+	MapPatternNodeType
+	VariantPatternNodeType
 )
 
 type ASTNode interface {
 	Type() NodeType
 	String() string
+	Position() source.Span
 }
 
+// Pos is embedded in every node and records the source it was parsed from
+type Pos struct {
+	Span source.Span
+}
+
+func (p *Pos) Position() source.Span { return p.Span }
+
 func indentStr(level int) string {
 	return strings.Repeat("  ", level)
 }
@@ -41,104 +83,373 @@ func pretty(node ASTNode, level int) string {
 	}
 
 	switch n := node.(type) {
-		case *ProgramNode:
-			sb := &strings.Builder{}
-			sb.WriteString(indentStr(level) + "ProgramNode {\n")
-			for _, stmt := range n.Statements {
-				sb.WriteString(pretty(stmt, level+1) + ",\n")
-			}
-			sb.WriteString(indentStr(level) + "}")
-			return sb.String()
-
-		case *BodyNode:
-			sb := &strings.Builder{}
-			sb.WriteString(indentStr(level) + "BodyNode {\n")
-			for _, stmt := range n.Statements {
-				sb.WriteString(pretty(stmt, level+1) + ",\n")
-			}
-			sb.WriteString(indentStr(level) + "}")
-			return sb.String()
-
-		case *VarDeclNode:
+	case *ProgramNode:
+		sb := &strings.Builder{}
+		sb.WriteString(indentStr(level) + "ProgramNode {\n")
+		for _, stmt := range n.Statements {
+			sb.WriteString(pretty(stmt, level+1) + ",\n")
+		}
+		sb.WriteString(indentStr(level) + "}")
+		return sb.String()
+
+	case *BodyNode:
+		sb := &strings.Builder{}
+		sb.WriteString(indentStr(level) + "BodyNode {\n")
+		for _, stmt := range n.Statements {
+			sb.WriteString(pretty(stmt, level+1) + ",\n")
+		}
+		sb.WriteString(indentStr(level) + "}")
+		return sb.String()
+
+	case *VarDeclNode:
+		if n.Pattern != nil {
 			return formatNode("VarDeclNode", level, map[string]ASTNode{
-				"Name":  &IdentifierNode{Name: n.Name},
-				"Value": n.Value,
-			})
-
-		case *AssignmentNode:
-			return formatNode("AssignmentNode", level, map[string]ASTNode{
-				"Name":  &IdentifierNode{Name: n.Name},
-				"Value": n.Value,
+				"Pattern": n.Pattern,
+				"Value":   n.Value,
 			})
-
-		case *FunctionCallNode:
-			sb := &strings.Builder{}
-
-			sb.WriteString(indentStr(level) + "FunctionCallNode {\n")
-			sb.WriteString(indentStr(level+1) + "Callee: " + n.Callee.String() + "\n")
-			sb.WriteString(indentStr(level+1) + "Arguments: [")
-
-			for i, arg := range n.Arguments {
-				if i > 0 {
-					sb.WriteString(", ")
-				}
-				sb.WriteString(arg.String())
+		}
+		return formatNode("VarDeclNode", level, map[string]ASTNode{
+			"Name":  &IdentifierNode{Name: n.Name},
+			"Value": n.Value,
+		})
+
+	case *ParallelAssignmentNode:
+		sb := &strings.Builder{}
+		sb.WriteString(indentStr(level) + "ParallelAssignmentNode {\n")
+		sb.WriteString(indentStr(level+1) + "Targets:\n")
+		for _, target := range n.Targets {
+			sb.WriteString(pretty(target, level+2) + ",\n")
+		}
+		sb.WriteString(indentStr(level+1) + "Values:\n")
+		for _, value := range n.Values {
+			sb.WriteString(pretty(value, level+2) + ",\n")
+		}
+		sb.WriteString(indentStr(level) + "}")
+		return sb.String()
+
+	case *AssignmentNode:
+		return formatNode("AssignmentNode", level, map[string]ASTNode{
+			"Target":   n.Target,
+			"Operator": &LiteralNode[string]{Value: n.Operator},
+			"Value":    n.Value,
+		})
+
+	case *FunctionCallNode:
+		sb := &strings.Builder{}
+
+		sb.WriteString(indentStr(level) + "FunctionCallNode {\n")
+		if n.Optional {
+			sb.WriteString(indentStr(level+1) + "Optional: true\n")
+		}
+		sb.WriteString(indentStr(level+1) + "Callee: " + n.Callee.String() + "\n")
+		sb.WriteString(indentStr(level+1) + "Arguments: [")
+
+		for i, arg := range n.Arguments {
+			if i > 0 {
+				sb.WriteString(", ")
 			}
+			sb.WriteString(arg.String())
+		}
 
-			sb.WriteString("]\n")
-			sb.WriteString(indentStr(level) + "}")
-			
-			return sb.String()
-
-		case *FunctionLiteralNode:
-			sb := &strings.Builder{}
-			sb.WriteString(indentStr(level) + "FunctionDeclNode {\n")
-			sb.WriteString(indentStr(level+1) + "Arguments: [")
-			for i, arg := range n.Arguments {
-				if i > 0 {
-					sb.WriteString(", ")
-				}
-				sb.WriteString(arg)
-			}
-			sb.WriteString("]\n")
-			sb.WriteString(indentStr(level+1) + "Statements:\n")
+		sb.WriteString("]\n")
+		sb.WriteString(indentStr(level) + "}")
 
-			for _, stmt := range n.Body.Statements {
-				sb.WriteString(pretty(stmt, level+2) + ",\n")
-			}
+		return sb.String()
 
-			sb.WriteString(indentStr(level) + "}")
-			return sb.String()
-		case *ReturnNode:
-			return indentStr(level) + "ReturnNode { Value: " + n.Value.String() + " }"
+	case *MethodCallNode:
+		sb := &strings.Builder{}
 
-		case *BinaryOpNode:
-			return formatNode("BinaryOpNode", level, map[string]ASTNode{
-				"Operator": &LiteralNode[string]{Value: n.Operator},
-				"Left":     n.Left,
-				"Right":    n.Right,
-			})
+		sb.WriteString(indentStr(level) + "MethodCallNode {\n")
+		sb.WriteString(indentStr(level+1) + "Object:\n")
+		sb.WriteString(pretty(n.Object, level+2) + "\n")
+		sb.WriteString(indentStr(level+1) + "Method: " + n.Method + "\n")
+		if n.Optional {
+			sb.WriteString(indentStr(level+1) + "Optional: true\n")
+		}
+		sb.WriteString(indentStr(level+1) + "Arguments: [")
 
-		case *UnaryOpNode:
-			return formatNode("UnaryOpNode", level, map[string]ASTNode{
-				"Operator": &LiteralNode[string]{Value: n.Operator},
-				"Operand":  n.Operand,
-			})
-
-		case *LiteralNode[int]:
-			return indentStr(level) + fmt.Sprintf("IntLiteralNode { Value: %v }", n.Value)
-
-		case *LiteralNode[float64]:
-			return indentStr(level) + fmt.Sprintf("FloatLiteralNode { Value: %v }", n.Value)
+		for i, arg := range n.Arguments {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(arg.String())
+		}
 
-		case *LiteralNode[string]:
-			return indentStr(level) + `StringLiteralNode { Value: "` + n.Value + `" }`
+		sb.WriteString("]\n")
+		sb.WriteString(indentStr(level) + "}")
 
-		case *IdentifierNode:
-			return indentStr(level) + "IdentifierNode { Name: " + n.Name + " }"
+		return sb.String()
 
-		default:
-			return indentStr(level) + node.String()
+	case *FunctionLiteralNode:
+		sb := &strings.Builder{}
+		sb.WriteString(indentStr(level) + "FunctionDeclNode {\n")
+		sb.WriteString(indentStr(level+1) + "Arguments: [")
+		for i, arg := range n.Arguments {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(arg)
+		}
+		sb.WriteString("]\n")
+		sb.WriteString(indentStr(level+1) + "Statements:\n")
+
+		for _, stmt := range n.Body.Statements {
+			sb.WriteString(pretty(stmt, level+2) + ",\n")
+		}
+
+		sb.WriteString(indentStr(level) + "}")
+		return sb.String()
+	case *ReturnNode:
+		if n.Value == nil {
+			return indentStr(level) + "ReturnNode {}"
+		}
+		return indentStr(level) + "ReturnNode { Value: " + n.Value.String() + " }"
+
+	case *IfNode:
+		sb := &strings.Builder{}
+		sb.WriteString(indentStr(level) + "IfNode {\n")
+		sb.WriteString(indentStr(level+1) + "Condition:\n")
+		sb.WriteString(pretty(n.Condition, level+2) + "\n")
+		sb.WriteString(indentStr(level+1) + "Consequent:\n")
+		sb.WriteString(pretty(n.Consequent, level+2) + "\n")
+		if n.Alternate != nil {
+			sb.WriteString(indentStr(level+1) + "Alternate:\n")
+			sb.WriteString(pretty(n.Alternate, level+2) + "\n")
+		}
+		sb.WriteString(indentStr(level) + "}")
+		return sb.String()
+
+	case *WhileNode:
+		return formatNode("WhileNode", level, map[string]ASTNode{
+			"Condition": n.Condition,
+			"Body":      n.Body,
+		})
+
+	case *ForNode:
+		return formatNode("ForNode", level, map[string]ASTNode{
+			"Init":      n.Init,
+			"Condition": n.Condition,
+			"Update":    n.Update,
+			"Body":      n.Body,
+		})
+
+	case *ForInNode:
+		return formatNode("ForInNode", level, map[string]ASTNode{
+			"Variable": &IdentifierNode{Name: n.Variable},
+			"Iterable": n.Iterable,
+			"Body":     n.Body,
+		})
+
+	case *DeleteNode:
+		return formatNode("DeleteNode", level, map[string]ASTNode{
+			"Target": n.Target,
+		})
+
+	case *BreakNode:
+		return indentStr(level) + "BreakNode {}"
+
+	case *ContinueNode:
+		return indentStr(level) + "ContinueNode {}"
+
+	case *TryNode:
+		// catch and finally are optional, leave out whichever is missing
+		fields := map[string]ASTNode{"Body": n.Body}
+		if n.Catch != nil {
+			fields["CatchName"] = &LiteralNode[string]{Value: n.CatchName}
+			fields["Catch"] = n.Catch
+		}
+		if n.Finally != nil {
+			fields["Finally"] = n.Finally
+		}
+		return formatNode("TryNode", level, fields)
+
+	case *StructDeclNode:
+		return indentStr(level) + fmt.Sprintf("StructDeclNode { Name: %s, Fields: [%s] }", n.Name, strings.Join(n.Fields, ", "))
+
+	case *ThrowNode:
+		return formatNode("ThrowNode", level, map[string]ASTNode{
+			"Value": n.Value,
+		})
+
+	case *BinaryOpNode:
+		return formatNode("BinaryOpNode", level, map[string]ASTNode{
+			"Operator": &LiteralNode[string]{Value: n.Operator},
+			"Left":     n.Left,
+			"Right":    n.Right,
+		})
+
+	case *UnaryOpNode:
+		return formatNode("UnaryOpNode", level, map[string]ASTNode{
+			"Operator": &LiteralNode[string]{Value: n.Operator},
+			"Operand":  n.Operand,
+		})
+
+	case *TernaryNode:
+		return formatNode("TernaryNode", level, map[string]ASTNode{
+			"Condition":  n.Condition,
+			"Consequent": n.Consequent,
+			"Alternate":  n.Alternate,
+		})
+
+	case *MatchNode:
+		sb := &strings.Builder{}
+		sb.WriteString(indentStr(level) + "MatchNode {\n")
+		sb.WriteString(indentStr(level+1) + "Subject:\n" + pretty(n.Subject, level+2) + "\n")
+		for _, arm := range n.Arms {
+			fields := map[string]ASTNode{"Pattern": arm.Pattern, "Body": arm.Body}
+			if arm.Guard != nil {
+				fields["Guard"] = arm.Guard
+			}
+			sb.WriteString(formatNode("Arm", level+1, fields) + ",\n")
+		}
+		sb.WriteString(indentStr(level) + "}")
+		return sb.String()
+
+	case *WildcardPatternNode:
+		return indentStr(level) + "WildcardPatternNode"
+
+	case *BindingPatternNode:
+		return indentStr(level) + "BindingPatternNode { Name: " + n.Name + " }"
+
+	case *LiteralPatternNode:
+		return formatNode("LiteralPatternNode", level, map[string]ASTNode{"Value": n.Value})
+
+	case *RangePatternNode:
+		return formatNode("RangePatternNode", level, map[string]ASTNode{
+			"Low":       n.Low,
+			"High":      n.High,
+			"Inclusive": &LiteralNode[string]{Value: fmt.Sprint(n.Inclusive)},
+		})
+
+	case *ArrayPatternNode:
+		sb := &strings.Builder{}
+		sb.WriteString(indentStr(level) + "ArrayPatternNode {\n")
+		for _, element := range n.Elements {
+			sb.WriteString(pretty(element, level+1) + ",\n")
+		}
+		if n.Rest != nil {
+			sb.WriteString(indentStr(level+1) + "Rest:\n" + pretty(n.Rest, level+2) + "\n")
+		}
+		sb.WriteString(indentStr(level) + "}")
+		return sb.String()
+
+	case *MapPatternNode:
+		fields := map[string]ASTNode{}
+		for i, key := range n.Keys {
+			fields[key] = n.Values[i]
+		}
+		return formatNode("MapPatternNode", level, fields)
+
+	case *VariantPatternNode:
+		sb := &strings.Builder{}
+		sb.WriteString(indentStr(level) + "VariantPatternNode { " + n.Enum + "." + n.Variant + "\n")
+		for _, field := range n.Fields {
+			sb.WriteString(pretty(field, level+1) + ",\n")
+		}
+		sb.WriteString(indentStr(level) + "}")
+		return sb.String()
+
+	case *InterpolationNode:
+		sb := &strings.Builder{}
+		sb.WriteString(indentStr(level) + "InterpolationNode {\n")
+		for _, part := range n.Parts {
+			sb.WriteString(pretty(part, level+1) + ",\n")
+		}
+		sb.WriteString(indentStr(level) + "}")
+		return sb.String()
+
+	case *ArrayLiteralNode:
+		sb := &strings.Builder{}
+		sb.WriteString(indentStr(level) + "ArrayLiteralNode {\n")
+		for _, element := range n.Elements {
+			sb.WriteString(pretty(element, level+1) + ",\n")
+		}
+		sb.WriteString(indentStr(level) + "}")
+		return sb.String()
+
+	case *MapLiteralNode:
+		sb := &strings.Builder{}
+		sb.WriteString(indentStr(level) + "MapLiteralNode {\n")
+		for i := range n.Keys {
+			sb.WriteString(indentStr(level+1) + "Entry:\n")
+			sb.WriteString(pretty(n.Keys[i], level+2) + "\n")
+			sb.WriteString(pretty(n.Values[i], level+2) + ",\n")
+		}
+		sb.WriteString(indentStr(level) + "}")
+		return sb.String()
+
+	case *IndexNode:
+		return formatNode("IndexNode", level, map[string]ASTNode{
+			"Object": n.Object,
+			"Index":  n.Index,
+		})
+
+	case *SliceNode:
+		return formatNode("SliceNode", level, map[string]ASTNode{
+			"Object": n.Object,
+			"Start":  n.Start,
+			"End":    n.End,
+		})
+
+	case *OptionalChainNode:
+		return formatNode("OptionalChainNode", level, map[string]ASTNode{"Chain": n.Chain})
+
+	case *MemberNode:
+		fields := map[string]ASTNode{
+			"Object":   n.Object,
+			"Property": &IdentifierNode{Name: n.Property},
+		}
+		if n.Optional {
+			fields["Optional"] = &LiteralNode[string]{Value: "true"}
+		}
+		return formatNode("MemberNode", level, fields)
+
+	case *LiteralNode[int]:
+		return indentStr(level) + fmt.Sprintf("IntLiteralNode { Value: %v }", n.Value)
+
+	case *LiteralNode[*big.Int]:
+		return indentStr(level) + fmt.Sprintf("IntLiteralNode { Value: %s }", n.Value)
+
+	case *LiteralNode[float64]:
+		return indentStr(level) + fmt.Sprintf("FloatLiteralNode { Value: %v }", n.Value)
+
+	case *LiteralNode[string]:
+		return indentStr(level) + `StringLiteralNode { Value: "` + n.Value + `" }`
+
+	case *IdentifierNode:
+		return indentStr(level) + "IdentifierNode { Name: " + n.Name + " }"
+
+	case *ThisNode:
+		return indentStr(level) + "ThisNode"
+
+	case *SuperNode:
+		return indentStr(level) + "SuperNode { Method: " + n.Method + " }"
+
+	case *EnumDeclNode:
+		variants := make([]string, len(n.Variants))
+		for i, variant := range n.Variants {
+			variants[i] = variant.Name
+			if variant.Fields != nil {
+				variants[i] += "(" + strings.Join(variant.Fields, ", ") + ")"
+			}
+		}
+		return indentStr(level) + fmt.Sprintf("EnumDeclNode { Name: %s, Variants: [%s] }", n.Name, strings.Join(variants, ", "))
+
+	case *ClassDeclNode:
+		sb := &strings.Builder{}
+		sb.WriteString(indentStr(level) + "ClassDeclNode {\n")
+		sb.WriteString(indentStr(level+1) + "Name: " + n.Name + "\n")
+		if n.Superclass != nil {
+			sb.WriteString(indentStr(level+1) + "Superclass: " + n.Superclass.Name + "\n")
+		}
+		for _, method := range n.Methods {
+			sb.WriteString(pretty(method, level+1) + ",\n")
+		}
+		sb.WriteString(indentStr(level) + "}")
+		return sb.String()
+
+	default:
+		return indentStr(level) + node.String()
 	}
 }
 
@@ -155,80 +466,449 @@ func formatNode(name string, level int, fields map[string]ASTNode) string {
 
 // ---------- Concrete AST Nodes ----------
 
-type ProgramNode struct{ Statements []ASTNode }
+type ProgramNode struct {
+	Pos
+	Statements []ASTNode
+}
+
 func (p *ProgramNode) Type() NodeType { return ProgramNodeType }
 func (p *ProgramNode) String() string { return pretty(p, 0) }
 
-type BodyNode struct { Statements []ASTNode }
+type BodyNode struct {
+	Pos
+	Statements []ASTNode
+}
+
 func (b *BodyNode) Type() NodeType { return BodyNodeType }
 func (b *BodyNode) String() string { return pretty(b, 0) }
 
+// var name = value, or with a Pattern instead of a Name, var [a, ...rest] = xs
+// and var {name, age} = person
 type VarDeclNode struct {
-	Name  string
-	Value ASTNode
+	Pos
+	Name    string
+	Pattern ASTNode // array or map pattern, nil for a plain name
+	Value   ASTNode
 }
+
 func (v *VarDeclNode) Type() NodeType { return VarDeclNodeType }
 func (v *VarDeclNode) String() string { return pretty(v, 0) }
 
+// Target is an *IdentifierNode, *IndexNode or *MemberNode.
+// Operator is "=" or a compound form like "+="; x++ / x-- are stored as "+=" / "-=" 1
 type AssignmentNode struct {
-	Name  string
-	Value ASTNode
+	Pos
+	Target   ASTNode
+	Operator string
+	Value    ASTNode
 }
+
 func (a *AssignmentNode) Type() NodeType { return AssignmentNodeType }
 func (a *AssignmentNode) String() string { return pretty(a, 0) }
 
+// a, b = b, a: every value is evaluated before any target is assigned. A
+// single value is unpacked when it is an array, a, b = pair.
+type ParallelAssignmentNode struct {
+	Pos
+	Targets []ASTNode
+	Values  []ASTNode
+}
+
+func (p *ParallelAssignmentNode) Type() NodeType { return ParallelAssignmentNodeType }
+func (p *ParallelAssignmentNode) String() string { return pretty(p, 0) }
+
+// Callee is any expression: an identifier, another call, an index, ...
 type FunctionCallNode struct {
-	Callee    *IdentifierNode
+	Pos
+	Callee    ASTNode
 	Arguments []ASTNode
+	Optional  bool // f?.(args), nil when the callee is nil
 }
 
 func (f *FunctionCallNode) Type() NodeType { return FunctionCallNodeType }
 func (f *FunctionCallNode) String() string { return pretty(f, 0) }
 
+// obj.method(args), kept apart from FunctionCallNode so the receiver is only evaluated once
+type MethodCallNode struct {
+	Pos
+	Object    ASTNode
+	Method    string
+	Arguments []ASTNode
+	Optional  bool // obj?.method(args)
+}
+
+func (m *MethodCallNode) Type() NodeType { return MethodCallNodeType }
+func (m *MethodCallNode) String() string { return pretty(m, 0) }
+
 type FunctionLiteralNode struct {
-	Arguments  []string
-	Body       *BodyNode
+	Pos
+	Name      string // "" for anonymous literals, used in stack traces
+	Arguments []string
+	Body      *BodyNode
 }
 
 func (f *FunctionLiteralNode) Type() NodeType { return FunctionLiteralNodeType }
 func (f *FunctionLiteralNode) String() string { return pretty(f, 0) }
 
 type ReturnNode struct {
+	Pos
 	Value ASTNode
 }
 
-func (r *ReturnNode) Type() NodeType { return ReturnNodeType}
+func (r *ReturnNode) Type() NodeType { return ReturnNodeType }
 func (r *ReturnNode) String() string { return pretty(r, 0) }
 
+// Alternate is nil, another *IfNode (else if) or a *BodyNode (else)
+type IfNode struct {
+	Pos
+	Condition  ASTNode
+	Consequent *BodyNode
+	Alternate  ASTNode
+}
+
+func (i *IfNode) Type() NodeType { return IfNodeType }
+func (i *IfNode) String() string { return pretty(i, 0) }
+
+type WhileNode struct {
+	Pos
+	Condition ASTNode
+	Body      *BodyNode
+}
+
+func (w *WhileNode) Type() NodeType { return WhileNodeType }
+func (w *WhileNode) String() string { return pretty(w, 0) }
+
+// Init, Condition and Update are all optional
+type ForNode struct {
+	Pos
+	Init      ASTNode
+	Condition ASTNode
+	Update    ASTNode
+	Body      *BodyNode
+}
+
+func (f *ForNode) Type() NodeType { return ForNodeType }
+func (f *ForNode) String() string { return pretty(f, 0) }
+
+type ForInNode struct {
+	Pos
+	Variable string
+	Iterable ASTNode
+	Body     *BodyNode
+}
+
+func (f *ForInNode) Type() NodeType { return ForInNodeType }
+func (f *ForInNode) String() string { return pretty(f, 0) }
+
+// delete m[k];
+type DeleteNode struct {
+	Pos
+	Target *IndexNode
+}
+
+func (d *DeleteNode) Type() NodeType { return DeleteNodeType }
+func (d *DeleteNode) String() string { return pretty(d, 0) }
+
+type BreakNode struct{ Pos }
+
+func (b *BreakNode) Type() NodeType { return BreakNodeType }
+func (b *BreakNode) String() string { return pretty(b, 0) }
+
+type ContinueNode struct{ Pos }
+
+func (c *ContinueNode) Type() NodeType { return ContinueNodeType }
+func (c *ContinueNode) String() string { return pretty(c, 0) }
+
+// try { } catch (e) { } finally { }, at least one of Catch / Finally is set
+type TryNode struct {
+	Pos
+	Body      *BodyNode
+	CatchName string
+	Catch     *BodyNode
+	Finally   *BodyNode
+}
+
+func (t *TryNode) Type() NodeType { return TryNodeType }
+func (t *TryNode) String() string { return pretty(t, 0) }
+
+// struct Point { x, y }
+type StructDeclNode struct {
+	Pos
+	Name   string
+	Fields []string
+}
+
+func (s *StructDeclNode) Type() NodeType { return StructDeclNodeType }
+func (s *StructDeclNode) String() string { return pretty(s, 0) }
+
+// class Name extends Base { init(...) {...} method(...) {...} }, each method
+// literal carries its own name
+type ClassDeclNode struct {
+	Pos
+	Name       string
+	Superclass *IdentifierNode // nil without extends
+	Methods    []*FunctionLiteralNode
+}
+
+func (c *ClassDeclNode) Type() NodeType { return ClassDeclNodeType }
+func (c *ClassDeclNode) String() string { return pretty(c, 0) }
+
+// enum Shape { Circle(r), Rect(w, h), Empty }
+type EnumDeclNode struct {
+	Pos
+	Name     string
+	Variants []EnumVariant
+}
+
+// Fields is nil for a variant without parentheses, like Empty
+type EnumVariant struct {
+	Name   string
+	Fields []string
+}
+
+func (e *EnumDeclNode) Type() NodeType { return EnumDeclNodeType }
+func (e *EnumDeclNode) String() string { return pretty(e, 0) }
+
+type ThrowNode struct {
+	Pos
+	Value ASTNode
+}
+
+func (t *ThrowNode) Type() NodeType { return ThrowNodeType }
+func (t *ThrowNode) String() string { return pretty(t, 0) }
+
 type BinaryOpNode struct {
+	Pos
 	Left, Right ASTNode
 	Operator    string
 }
+
 func (b *BinaryOpNode) Type() NodeType { return BinaryOpNodeType }
 func (b *BinaryOpNode) String() string { return pretty(b, 0) }
 
 type UnaryOpNode struct {
+	Pos
 	Operator string
 	Operand  ASTNode
 }
+
 func (u *UnaryOpNode) Type() NodeType { return UnaryOpNodeType }
 func (u *UnaryOpNode) String() string { return pretty(u, 0) }
 
-type IdentifierNode struct{ Name string }
+// cond ? a : b
+type TernaryNode struct {
+	Pos
+	Condition  ASTNode
+	Consequent ASTNode
+	Alternate  ASTNode
+}
+
+func (t *TernaryNode) Type() NodeType { return TernaryNodeType }
+func (t *TernaryNode) String() string { return pretty(t, 0) }
+
+// match subject { pattern [if guard] => expr, ... }, the first arm whose
+// pattern matches and whose guard holds is evaluated
+type MatchNode struct {
+	Pos
+	Subject ASTNode
+	Arms    []MatchArm
+}
+
+type MatchArm struct {
+	Pattern ASTNode
+	Guard   ASTNode // nil without 'if'
+	Body    ASTNode
+}
+
+func (m *MatchNode) Type() NodeType { return MatchNodeType }
+func (m *MatchNode) String() string { return pretty(m, 0) }
+
+// "Hello ${name}!": string literals and expressions, concatenated in order
+type InterpolationNode struct {
+	Pos
+	Parts []ASTNode
+}
+
+func (i *InterpolationNode) Type() NodeType { return InterpolationNodeType }
+func (i *InterpolationNode) String() string { return pretty(i, 0) }
+
+type ArrayLiteralNode struct {
+	Pos
+	Elements []ASTNode
+}
+
+func (a *ArrayLiteralNode) Type() NodeType { return ArrayLiteralNodeType }
+func (a *ArrayLiteralNode) String() string { return pretty(a, 0) }
+
+// {"key": value, ...}, Keys[i] pairs with Values[i]
+type MapLiteralNode struct {
+	Pos
+	Keys   []ASTNode
+	Values []ASTNode
+}
+
+func (m *MapLiteralNode) Type() NodeType { return MapLiteralNodeType }
+func (m *MapLiteralNode) String() string { return pretty(m, 0) }
+
+// xs[i]
+type IndexNode struct {
+	Pos
+	Object ASTNode
+	Index  ASTNode
+}
+
+func (i *IndexNode) Type() NodeType { return IndexNodeType }
+func (i *IndexNode) String() string { return pretty(i, 0) }
+
+// xs[start:end], either bound may be nil
+type SliceNode struct {
+	Pos
+	Object     ASTNode
+	Start, End ASTNode
+}
+
+func (s *SliceNode) Type() NodeType { return SliceNodeType }
+func (s *SliceNode) String() string { return pretty(s, 0) }
+
+// obj.property, or obj?.property which is nil when obj is nil; the rest of
+// the chain is then skipped too, see OptionalChainNode
+type MemberNode struct {
+	Pos
+	Object   ASTNode
+	Property string
+	Optional bool
+}
+
+func (m *MemberNode) Type() NodeType { return MemberNodeType }
+func (m *MemberNode) String() string { return pretty(m, 0) }
+
+// wraps a postfix chain containing ?. so that a ?. step meeting nil makes the
+// whole chain nil: o?.a.b(c)[0] is nil when o is nil, otherwise the later
+// steps run as usual
+type OptionalChainNode struct {
+	Pos
+	Chain ASTNode
+}
+
+func (o *OptionalChainNode) Type() NodeType { return OptionalChainNodeType }
+func (o *OptionalChainNode) String() string { return pretty(o, 0) }
+
+type IdentifierNode struct {
+	Pos
+	Name string
+}
+
 func (i *IdentifierNode) Type() NodeType { return IdentifierNodeType }
 func (i *IdentifierNode) String() string { return pretty(i, 0) }
 
-type LiteralNode[T int | float64 | string] struct { Value T }
+// the instance a method was called on
+type ThisNode struct {
+	Pos
+}
+
+func (t *ThisNode) Type() NodeType { return ThisNodeType }
+func (t *ThisNode) String() string { return pretty(t, 0) }
+
+// super.method, the superclass's version bound to this
+type SuperNode struct {
+	Pos
+	Method string
+}
+
+func (s *SuperNode) Type() NodeType { return SuperNodeType }
+func (s *SuperNode) String() string { return pretty(s, 0) }
+
+// ints too large for a Go int are parsed into a *big.Int
+type LiteralNode[T int | float64 | string | *big.Int] struct {
+	Pos
+	Value T
+}
+
 func (l *LiteralNode[T]) Type() NodeType {
 	switch any(l.Value).(type) {
-		case int:
-			return IntLiteralNodeType
-		case float64:
-			return FloatLiteralNodeType
-		case string:
-			return StringLiteralNodeType
-		default:
-			panic("unknown literal type")
+	case int:
+		return IntLiteralNodeType
+	case *big.Int:
+		return IntLiteralNodeType
+	case float64:
+		return FloatLiteralNodeType
+	case string:
+		return StringLiteralNodeType
+	default:
+		panic("unknown literal type")
 	}
 }
-func (l *LiteralNode[T]) String() string { return pretty(l, 0) }
\ No newline at end of file
+func (l *LiteralNode[T]) String() string { return pretty(l, 0) }
+
+// ---------- Patterns ----------
+// used by match arms, destructuring var declarations and parameter lists; a
+// pattern either matches a value, binding the names it contains, or it doesn't
+
+// _ matches anything and binds nothing
+type WildcardPatternNode struct {
+	Pos
+}
+
+func (w *WildcardPatternNode) Type() NodeType { return WildcardPatternNodeType }
+func (w *WildcardPatternNode) String() string { return pretty(w, 0) }
+
+// a bare name matches anything and binds it
+type BindingPatternNode struct {
+	Pos
+	Name string
+}
+
+func (b *BindingPatternNode) Type() NodeType { return BindingPatternNodeType }
+func (b *BindingPatternNode) String() string { return pretty(b, 0) }
+
+// 42, -1.5, "text", true, nil: matches values == to Value
+type LiteralPatternNode struct {
+	Pos
+	Value ASTNode
+}
+
+func (l *LiteralPatternNode) Type() NodeType { return LiteralPatternNodeType }
+func (l *LiteralPatternNode) String() string { return pretty(l, 0) }
+
+// 1..5 leaves out 5, 1..=5 includes it
+type RangePatternNode struct {
+	Pos
+	Low       ASTNode
+	High      ASTNode
+	Inclusive bool
+}
+
+func (r *RangePatternNode) Type() NodeType { return RangePatternNodeType }
+func (r *RangePatternNode) String() string { return pretty(r, 0) }
+
+// [a, b] needs exactly two elements, [a, b, ...rest] at least two
+type ArrayPatternNode struct {
+	Pos
+	Elements []ASTNode
+	Rest     ASTNode // binding or wildcard after '...', nil without one
+}
+
+func (a *ArrayPatternNode) Type() NodeType { return ArrayPatternNodeType }
+func (a *ArrayPatternNode) String() string { return pretty(a, 0) }
+
+// {name, age: a} matches maps holding those keys, or objects with those
+// fields; anything else the value has is ignored. {name} is {name: name}.
+type MapPatternNode struct {
+	Pos
+	Keys   []string
+	Values []ASTNode
+}
+
+func (m *MapPatternNode) Type() NodeType { return MapPatternNodeType }
+func (m *MapPatternNode) String() string { return pretty(m, 0) }
+
+// Shape.Circle(r); without parentheses only the tag is checked
+type VariantPatternNode struct {
+	Pos
+	Enum    string
+	Variant string
+	Fields  []ASTNode // nil without parentheses
+}
+
+func (v *VariantPatternNode) Type() NodeType { return VariantPatternNodeType }
+func (v *VariantPatternNode) String() string { return pretty(v, 0) }
@@ -3,13 +3,99 @@ package lexer
 
 import (
 	"fmt"
-	"strings"
+	"pcl/src/frontend/source"
+	"unicode"
+	"unicode/utf8"
 )
 
 type Lexer struct {
+	// added to the byte offsets of every span, the repl moves it past the
+	// previous inputs so spans from different inputs never overlap
+	Offset int
+
 	sourceCode  string
 	pos         int
 	currentChar byte
+
+	// 1-based position of currentChar
+	line   int
+	column int
+
+	diagnostics []source.Diagnostic
+
+	// one entry per "${" still open, innermost last
+	interpolations []interpolation
+
+	// opening quote of the first string that ran past the end of its line,
+	// the likeliest culprit when a later string is left unterminated
+	spannedLines source.Span
+}
+
+type interpolation struct {
+	open  source.Span // the "${", for errors
+	quote source.Span // the opening quote of the string it sits in
+	depth int         // '{' seen inside the expression and not yet closed
+}
+
+var keywords = map[string]TokenType{
+	"var":      VarToken,
+	"if":       IfToken,
+	"else":     ElseToken,
+	"for":      ForToken,
+	"in":       InToken,
+	"while":    WhileToken,
+	"func":     FuncToken,
+	"return":   ReturnToken,
+	"delete":   DeleteToken,
+	"break":    BreakToken,
+	"continue": ContinueToken,
+	"try":      TryToken,
+	"catch":    CatchToken,
+	"finally":  FinallyToken,
+	"throw":    ThrowToken,
+	"struct":   StructToken,
+	"class":    ClassToken,
+	"extends":  ExtendsToken,
+	"this":     ThisToken,
+	"super":    SuperToken,
+	"enum":     EnumToken,
+	"match":    MatchToken,
+}
+
+// isFloorDiv decides what a '//' at the current position is. It is floor
+// division only when it directly follows an operand on the same line and
+// is immediately followed by more code, as in 7//2 or a //b. A '//'
+// followed by a space or the end of the line always starts a comment, so
+// f(x) // note and a // b are comments and floor division with spaces
+// is written a //b or a//b.
+func (lexer *Lexer) isFloorDiv(tokens []Token) bool {
+	switch lexer.peekAt(2) {
+	case 0, ' ', '\t', '\r', '\n':
+		return false
+	}
+
+	if len(tokens) == 0 {
+		return false
+	}
+	last := tokens[len(tokens)-1]
+	if last.Span.Line != lexer.line {
+		return false
+	}
+
+	switch last.Type {
+	case IdentifierToken, NumberToken, StringToken, RParenToken, RBracketToken:
+		return true
+	}
+	return false
+}
+
+// Keywords lists every reserved word, for "did you mean" suggestions
+func Keywords() []string {
+	names := make([]string, 0, len(keywords))
+	for name := range keywords {
+		names = append(names, name)
+	}
+	return names
 }
 
 func isDigit(c byte) bool {
@@ -20,14 +106,29 @@ func isLetter(c byte) bool {
 	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_'
 }
 
+// identifiers may use any unicode letter, not just ascii ones
+func isIdentifierStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentifierPart(r rune) bool {
+	return isIdentifierStart(r) || unicode.IsDigit(r)
+}
+
 func NewLexer(sourceCode string) *Lexer {
 	return &Lexer{
 		sourceCode: sourceCode,
 		pos:        -1,
+		line:       1,
 	}
 }
 
 func (lexer *Lexer) Advance() {
+	if lexer.currentChar == '\n' {
+		lexer.line++
+		lexer.column = 0
+	}
+
 	lexer.pos++
 
 	if lexer.pos >= len(lexer.sourceCode) {
@@ -35,6 +136,26 @@ func (lexer *Lexer) Advance() {
 	} else {
 		lexer.currentChar = lexer.sourceCode[lexer.pos]
 	}
+
+	// columns count characters, so skip utf-8 continuation bytes
+	if lexer.currentChar&0xC0 != 0x80 {
+		lexer.column++
+	}
+}
+
+// currentRune decodes the whole utf-8 character starting at currentChar
+func (lexer *Lexer) currentRune() (rune, int) {
+	if lexer.currentChar == 0 {
+		return 0, 0
+	}
+	return utf8.DecodeRuneInString(lexer.sourceCode[lexer.pos:])
+}
+
+func (lexer *Lexer) advanceRune() {
+	_, size := lexer.currentRune()
+	for i := 0; i < max(size, 1); i++ {
+		lexer.Advance()
+	}
 }
 
 func (lexer *Lexer) Eat() byte {
@@ -44,36 +165,126 @@ func (lexer *Lexer) Eat() byte {
 }
 
 func (lexer *Lexer) Peek() byte {
-	np := lexer.pos + 1
+	return lexer.peekAt(1)
+}
+
+// peekAt looks n characters past currentChar, 0 past the end
+func (lexer *Lexer) peekAt(n int) byte {
+	np := lexer.pos + n
 	if np >= len(lexer.sourceCode) {
 		return 0
 	}
 	return lexer.sourceCode[np]
 }
 
-func (lexer *Lexer) Tokenize() []Token {
+// span of a single character at the current position
+func (lexer *Lexer) here() source.Span {
+	return source.Span{Start: lexer.pos, End: lexer.pos + 1, Line: lexer.line, Column: lexer.column}
+}
+
+func (lexer *Lexer) errorAt(span source.Span, label string, format string, args ...any) {
+	lexer.diagnostics = append(lexer.diagnostics, source.Diagnostic{
+		Message: fmt.Sprintf(format, args...),
+		Span:    span,
+		Label:   label,
+	})
+}
+
+// Tokenize scans the whole source. Anything it cannot make sense of becomes an
+// ErrorToken plus a diagnostic, and scanning carries on so every lexical error
+// in the file is reported at once.
+func (lexer *Lexer) Tokenize() ([]Token, []source.Diagnostic) {
 	var tokens []Token
 
+	// where the token currently being scanned began
+	var start, startLine, startColumn int
+
 	add := func(tType TokenType, val string) {
-		tokens = append(tokens, Token{Type: tType, Value: val})
+		tokens = append(tokens, Token{
+			Type:  tType,
+			Value: val,
+			Span:  source.Span{Start: start, End: lexer.pos, Line: startLine, Column: startColumn},
+		})
+	}
+
+	// after an opening quote, or the '}' that ends an interpolation: emit the
+	// text up to the closing quote as complete, or up to the next "${" as
+	// interpolated. quote is the string's opening quote, for errors
+	stringPart := func(quote source.Span, complete, interpolated TokenType) {
+		text, end := lexer.scanStringBody()
+		if lexer.line != startLine && lexer.spannedLines.Line == 0 {
+			lexer.spannedLines = quote
+		}
+
+		switch end {
+		case stringClosed:
+			add(complete, text)
+		case stringInterpolates:
+			add(interpolated, text)
+			open := lexer.here() // just past the "${"
+			open.Start, open.End, open.Column = open.Start-2, open.Start, open.Column-2
+			lexer.interpolations = append(lexer.interpolations, interpolation{open: open, quote: quote})
+		default:
+			lexer.errorAt(quote, "missing closing '\"'", "unterminated string literal")
+			if earlier := lexer.spannedLines; earlier.Line != 0 && earlier.Start != quote.Start {
+				last := &lexer.diagnostics[len(lexer.diagnostics)-1]
+				last.Hint = fmt.Sprintf("the string opened at %d:%d runs on past its line, if that one lost its closing quote the quotes after it pair up wrongly", earlier.Line, earlier.Column)
+			}
+			add(ErrorToken, lexer.sourceCode[start:lexer.pos])
+		}
 	}
 
 	lexer.Advance()
 
 	for lexer.currentChar != 0 {
+		start, startLine, startColumn = lexer.pos, lexer.line, lexer.column
+
 		switch lexer.currentChar {
 
 		case '+':
-			add(PlusToken, string(lexer.Eat()))
+			lexer.Advance()
+			if lexer.currentChar == '+' {
+				lexer.Advance()
+				add(IncrementToken, "++")
+			} else if lexer.currentChar == '=' {
+				lexer.Advance()
+				add(PlusEqualToken, "+=")
+			} else {
+				add(PlusToken, "+")
+			}
 			continue
 		case '-':
-			add(MinusToken, string(lexer.Eat()))
+			lexer.Advance()
+			if lexer.currentChar == '-' {
+				lexer.Advance()
+				add(DecrementToken, "--")
+			} else if lexer.currentChar == '=' {
+				lexer.Advance()
+				add(MinusEqualToken, "-=")
+			} else {
+				add(MinusToken, "-")
+			}
 			continue
 		case '*':
-			add(StarToken, string(lexer.Eat()))
+			lexer.Advance()
+			if lexer.currentChar == '*' {
+				lexer.Advance()
+				add(PowerToken, "**")
+			} else if lexer.currentChar == '=' {
+				lexer.Advance()
+				add(StarEqualToken, "*=")
+			} else {
+				add(StarToken, "*")
+			}
 			continue
 		case '%':
-			add(PercentToken, string(lexer.Eat()))
+			lexer.Advance()
+			if lexer.currentChar == '=' {
+				lexer.Advance()
+				add(PercentEqualToken, "%=")
+			} else {
+				add(PercentToken, "%")
+			}
 			continue
 		case '(':
 			add(LParenToken, string(lexer.Eat()))
@@ -82,9 +293,23 @@ func (lexer *Lexer) Tokenize() []Token {
 			add(RParenToken, string(lexer.Eat()))
 			continue
 		case '{':
+			if open := len(lexer.interpolations); open > 0 {
+				lexer.interpolations[open-1].depth++
+			}
 			add(LBraceToken, string(lexer.Eat()))
 			continue
 		case '}':
+			// the '}' closing an interpolation picks the string back up
+			if open := len(lexer.interpolations); open > 0 {
+				if lexer.interpolations[open-1].depth == 0 {
+					quote := lexer.interpolations[open-1].quote
+					lexer.interpolations = lexer.interpolations[:open-1]
+					lexer.Eat()
+					stringPart(quote, StringTailToken, StringMiddleToken)
+					continue
+				}
+				lexer.interpolations[open-1].depth--
+			}
 			add(RBraceToken, string(lexer.Eat()))
 			continue
 		case '[':
@@ -99,13 +324,59 @@ func (lexer *Lexer) Tokenize() []Token {
 		case ',':
 			add(CommaToken, string(lexer.Eat()))
 			continue
+		case ':':
+			add(ColonToken, string(lexer.Eat()))
+			continue
 		case '.':
-			add(DotToken, string(lexer.Eat()))
+			// a leading-dot float, .5
+			if isDigit(lexer.Peek()) {
+				add(NumberToken, lexer.scanNumber())
+				continue
+			}
+			// '..' and '..=' only appear in range patterns, '...' in rest patterns
+			lexer.Advance()
+			if lexer.currentChar != '.' {
+				add(DotToken, ".")
+				continue
+			}
+			lexer.Advance()
+			switch lexer.currentChar {
+			case '.':
+				lexer.Advance()
+				add(EllipsisToken, "...")
+			case '=':
+				lexer.Advance()
+				add(RangeInclusiveToken, "..=")
+			default:
+				add(RangeToken, "..")
+			}
 			continue
 		case '~':
 			add(BitwiseNotToken, string(lexer.Eat()))
 			continue
+		case '^':
+			add(BitwiseXorToken, string(lexer.Eat()))
+			continue
+		case '?':
+			lexer.Advance()
+			if lexer.currentChar == '?' {
+				lexer.Advance()
+				add(NilCoalesceToken, "??")
+			} else if lexer.currentChar == '.' && !isDigit(lexer.Peek()) {
+				// c ?.5 : 1 is a ternary with a leading-dot float, not ?.
+				lexer.Advance()
+				add(OptionalDotToken, "?.")
+			} else {
+				add(QuestionToken, "?")
+			}
+			continue
 		case '/':
+			if lexer.Peek() == '/' && lexer.isFloorDiv(tokens) {
+				lexer.Advance()
+				lexer.Advance()
+				add(FloorDivToken, "//")
+				continue
+			}
 			if lexer.Peek() == '/' {
 				lexer.Eat()
 				lexer.Eat()
@@ -114,7 +385,13 @@ func (lexer *Lexer) Tokenize() []Token {
 				}
 				continue
 			}
-			add(SlashToken, string(lexer.Eat()))
+			lexer.Advance()
+			if lexer.currentChar == '=' {
+				lexer.Advance()
+				add(SlashEqualToken, "/=")
+			} else {
+				add(SlashToken, "/")
+			}
 			continue
 
 		case '=':
@@ -122,13 +399,19 @@ func (lexer *Lexer) Tokenize() []Token {
 			if lexer.currentChar == '=' {
 				lexer.Advance()
 				add(DoubleEqualToken, "==")
+			} else if lexer.currentChar == '>' {
+				lexer.Advance()
+				add(FatArrowToken, "=>")
 			} else {
 				add(EqualToken, "=")
 			}
 			continue
 		case '<':
 			lexer.Advance()
-			if lexer.currentChar == '=' {
+			if lexer.currentChar == '<' {
+				lexer.Advance()
+				add(ShiftLeftToken, "<<")
+			} else if lexer.currentChar == '=' {
 				lexer.Advance()
 				add(LessEqualToken, "<=")
 			} else {
@@ -137,7 +420,10 @@ func (lexer *Lexer) Tokenize() []Token {
 			continue
 		case '>':
 			lexer.Advance()
-			if lexer.currentChar == '=' {
+			if lexer.currentChar == '>' {
+				lexer.Advance()
+				add(ShiftRightToken, ">>")
+			} else if lexer.currentChar == '=' {
 				lexer.Advance()
 				add(GreaterEqualToken, ">=")
 			} else {
@@ -177,92 +463,69 @@ func (lexer *Lexer) Tokenize() []Token {
 			continue
 
 		case '"':
+			quote := lexer.here()
 			lexer.Eat()
-			var b strings.Builder
-			
-			for lexer.currentChar != 0 && lexer.currentChar != '"' {
-				if lexer.currentChar == '\\' && lexer.Peek() != 0 {
-					lexer.Eat()
-					switch lexer.currentChar {
-					case 'n':
-						b.WriteByte('\n')
-					case 't':
-						b.WriteByte('\t')
-					case '"':
-						b.WriteByte('"')
-					case '\\':
-						b.WriteByte('\\')
-					default:
-						b.WriteByte(lexer.currentChar)
-					}
-					lexer.Advance()
-					continue
-				}
-				b.WriteByte(lexer.currentChar)
+			stringPart(quote, StringToken, StringHeadToken)
+			continue
+
+		// `raw` strings: no escapes, no interpolation, may span lines
+		case '`':
+			lexer.Eat()
+			for lexer.currentChar != 0 && lexer.currentChar != '`' {
 				lexer.Advance()
 			}
-			if lexer.currentChar == '"' {
-				lexer.Eat()
+			if lexer.currentChar != '`' {
+				lexer.errorAt(source.Span{Start: start, End: lexer.pos, Line: startLine, Column: startColumn}, "missing closing '`'", "unterminated raw string literal")
+				add(ErrorToken, lexer.sourceCode[start:lexer.pos])
+				continue
 			}
-			add(StringToken, b.String())
+			text := lexer.sourceCode[start+1 : lexer.pos]
+			lexer.Eat()
+			add(StringToken, text)
 			continue
 
 		default:
 			if isDigit(lexer.currentChar) {
-				var b strings.Builder
-				
-				for isDigit(lexer.currentChar) {
-					b.WriteByte(lexer.currentChar)
-					lexer.Advance()
-				}
-
-				if lexer.currentChar == '.' && isDigit(lexer.Peek()) {
-					b.WriteByte('.')
-					lexer.Advance()
-
-					for isDigit(lexer.currentChar) {
-						b.WriteByte(lexer.currentChar)
-						lexer.Advance()
-					}
-				}
-
-				add(NumberToken, b.String())
+				add(NumberToken, lexer.scanNumber())
 				continue
 			}
 
-			if isLetter(lexer.currentChar) {
-				var b strings.Builder
-				for isLetter(lexer.currentChar) || isDigit(lexer.currentChar) {
-					b.WriteByte(lexer.currentChar)
-					lexer.Advance()
+			if char, _ := lexer.currentRune(); isIdentifierStart(char) {
+				for char, _ := lexer.currentRune(); isIdentifierPart(char); char, _ = lexer.currentRune() {
+					lexer.advanceRune()
 				}
-				idStr := b.String()
-				switch idStr {
-				case "var":
-					add(VarToken, idStr)
-				case "if":
-					add(IfToken, idStr)
-				case "else":
-					add(ElseToken, idStr)
-				case "for":
-					add(ForToken, idStr)
-				case "while":
-					add(WhileToken, idStr)
-				case "func":
-					add(FuncToken, idStr)
-				case "return":
-					add(ReturnToken, idStr)
-				default:
+				idStr := lexer.sourceCode[start:lexer.pos]
+				if keyword, ok := keywords[idStr]; ok {
+					add(keyword, idStr)
+				} else {
 					add(IdentifierToken, idStr)
 				}
 				continue
 			}
 
-			fmt.Printf("unrecognized character '%c' at pos %d\n", lexer.currentChar, lexer.pos)
-			lexer.Advance()
+			// skip the whole character, not just its first utf-8 byte
+			char, _ := lexer.currentRune()
+			lexer.advanceRune()
+			lexer.errorAt(source.Span{Start: start, End: lexer.pos, Line: startLine, Column: startColumn}, "", "unexpected character %q", char)
+			add(ErrorToken, string(char))
 		}
 	}
 
+	for _, open := range lexer.interpolations {
+		lexer.errorAt(open.open, "opened here", "unterminated string interpolation")
+	}
+
+	start, startLine, startColumn = len(lexer.sourceCode), lexer.line, lexer.column
 	add(EOFToken, "EOF")
-	return tokens
+
+	if lexer.Offset != 0 {
+		for i := range tokens {
+			tokens[i].Span = tokens[i].Span.Shift(lexer.Offset)
+		}
+		for i := range lexer.diagnostics {
+			lexer.diagnostics[i].Span = lexer.diagnostics[i].Span.Shift(lexer.Offset)
+		}
+	}
+
+	return tokens, lexer.diagnostics
 }
@@ -0,0 +1,146 @@
+package lexer
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isOctalDigit(c byte) bool  { return c >= '0' && c <= '7' }
+func isBinaryDigit(c byte) bool { return c == '0' || c == '1' }
+
+// scanNumber consumes a numeric literal and returns its text unchanged:
+// decimal ints and floats (.5 included) with an optional exponent (6.02e23), or 0x / 0o / 0b
+// prefixed ints, all of which may use '_' between digits (1_000_000)
+func (lexer *Lexer) scanNumber() string {
+	start := lexer.pos
+
+	if lexer.currentChar == '0' {
+		var valid func(byte) bool
+		var name string
+
+		switch lexer.Peek() {
+		case 'x', 'X':
+			valid, name = isHexDigit, "hexadecimal"
+		case 'o', 'O':
+			valid, name = isOctalDigit, "octal"
+		case 'b', 'B':
+			valid, name = isBinaryDigit, "binary"
+		}
+
+		if valid != nil {
+			prefix := lexer.here()
+			lexer.Advance()
+			lexer.Advance()
+
+			if lexer.scanDigits(valid) == 0 && !isLetter(lexer.currentChar) && !isDigit(lexer.currentChar) {
+				prefix.End = lexer.pos
+				lexer.errorAt(prefix, "", "%s literal has no digits", name)
+			}
+
+			// 0b102, 0xFG: report the first bad digit and swallow the rest
+			if isLetter(lexer.currentChar) || isDigit(lexer.currentChar) {
+				lexer.errorAt(lexer.here(), "", "invalid digit '%c' in %s literal", lexer.currentChar, name)
+				for isLetter(lexer.currentChar) || isDigit(lexer.currentChar) {
+					lexer.Advance()
+				}
+			}
+
+			return lexer.sourceCode[start:lexer.pos]
+		}
+	}
+
+	lexer.scanDigits(isDigit)
+
+	if lexer.currentChar == '.' && isDigit(lexer.Peek()) {
+		lexer.Advance()
+		lexer.scanDigits(isDigit)
+	}
+
+	// only an exponent if digits follow, otherwise the e starts an identifier
+	if lexer.currentChar == 'e' || lexer.currentChar == 'E' {
+		next := lexer.Peek()
+		if next == '+' || next == '-' {
+			if lexer.pos+2 < len(lexer.sourceCode) && isDigit(lexer.sourceCode[lexer.pos+2]) {
+				lexer.Advance()
+				lexer.Advance()
+				lexer.scanDigits(isDigit)
+			}
+		} else if isDigit(next) {
+			lexer.Advance()
+			lexer.scanDigits(isDigit)
+		}
+	}
+
+	return lexer.sourceCode[start:lexer.pos]
+}
+
+// scanDigits consumes digits and the '_' separators between them, returning
+// how many digits it saw
+func (lexer *Lexer) scanDigits(valid func(byte) bool) int {
+	count := 0
+	for valid(lexer.currentChar) || lexer.currentChar == '_' {
+		if lexer.currentChar == '_' && (count == 0 || !valid(lexer.Peek())) {
+			lexer.errorAt(lexer.here(), "", "'_' must separate digits")
+		}
+		if lexer.currentChar != '_' {
+			count++
+		}
+		lexer.Advance()
+	}
+	return count
+}
+
+// IsFloatLiteral tells the two kinds of number token apart
+func IsFloatLiteral(text string) bool {
+	if len(text) > 1 && text[0] == '0' && strings.ContainsRune("xXoObB", rune(text[1])) {
+		return false // 0xE is an int
+	}
+	return strings.ContainsAny(text, ".eE")
+}
+
+// ParseInt reads the text of an int literal, with or without a base prefix and
+// digit separators; a leading sign is allowed so int("-12") can share it
+func ParseInt(text string) (*big.Int, bool) {
+	text = strings.ReplaceAll(text, "_", "")
+
+	negative := false
+	if strings.HasPrefix(text, "-") || strings.HasPrefix(text, "+") {
+		negative = text[0] == '-'
+		text = text[1:]
+	}
+
+	base := 10
+	if len(text) > 2 && text[0] == '0' {
+		switch text[1] {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		}
+		if base != 10 {
+			text = text[2:]
+		}
+	}
+
+	n, ok := new(big.Int).SetString(text, base)
+	if !ok {
+		return nil, false
+	}
+	if negative {
+		n.Neg(n)
+	}
+	return n, true
+}
+
+// ParseFloat reads the text of a float literal
+func ParseFloat(text string) (float64, bool) {
+	f, err := strconv.ParseFloat(strings.ReplaceAll(text, "_", ""), 64)
+	return f, err == nil
+}
@@ -0,0 +1,121 @@
+package lexer
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+type stringEnd int
+
+const (
+	stringClosed       stringEnd = iota // ended at the closing quote
+	stringInterpolates                  // stopped at a "${"
+	stringUnterminated                  // ran into the end of input
+)
+
+// scanStringBody reads the inside of a double quoted string up to its closing
+// quote or its next "${", consuming either, and decodes escapes on the way.
+// Strings may span lines, only reaching the end of input leaves one open.
+func (lexer *Lexer) scanStringBody() (string, stringEnd) {
+	var b strings.Builder
+
+	for lexer.currentChar != '"' {
+		switch lexer.currentChar {
+		case 0:
+			return b.String(), stringUnterminated
+		case '$':
+			if lexer.Peek() == '{' {
+				lexer.Advance()
+				lexer.Advance()
+				return b.String(), stringInterpolates
+			}
+		case '\\':
+			lexer.scanEscape(&b)
+			continue
+		}
+
+		b.WriteByte(lexer.currentChar)
+		lexer.Advance()
+	}
+
+	lexer.Eat() // eat the closing '"'
+	return b.String(), stringClosed
+}
+
+// scanEscape decodes one backslash escape into b: \n \t \r \" \\ \$, \x41
+// (two hex digits) and \u{1F600} (one to six hex digits)
+func (lexer *Lexer) scanEscape(b *strings.Builder) {
+	escape := lexer.here()
+	lexer.Eat() // eat '\'
+
+	simple := map[byte]byte{'n': '\n', 't': '\t', 'r': '\r', '"': '"', '\\': '\\', '$': '$'}
+	if c, ok := simple[lexer.currentChar]; ok {
+		b.WriteByte(c)
+		lexer.Advance()
+		return
+	}
+
+	switch lexer.currentChar {
+	case 0:
+		return // reported as unterminated by the caller
+	case 'x':
+		lexer.Advance()
+		digits := lexer.scanHex(2)
+		escape.End = lexer.pos
+		if len(digits) != 2 {
+			lexer.errorAt(escape, "expected two hex digits", "invalid escape sequence")
+			return
+		}
+		b.WriteRune(hexValue(digits))
+	case 'u':
+		lexer.Advance()
+		if lexer.currentChar != '{' {
+			escape.End = lexer.pos
+			lexer.errorAt(escape, "expected '{'", "invalid unicode escape, write it as \\u{1F600}")
+			return
+		}
+		lexer.Advance()
+		digits := lexer.scanHex(6)
+		closed := lexer.currentChar == '}'
+		if closed {
+			lexer.Advance()
+		}
+		escape.End = lexer.pos
+
+		char := hexValue(digits)
+		if !closed || digits == "" || !utf8.ValidRune(char) {
+			lexer.errorAt(escape, "not a unicode character", "invalid unicode escape")
+			return
+		}
+		b.WriteRune(char)
+	default:
+		escape.End = lexer.pos + 1
+		lexer.errorAt(escape, "unknown escape", "invalid escape sequence '\\%c'", lexer.currentChar)
+		lexer.advanceRune()
+	}
+}
+
+// scanHex consumes up to limit hex digits
+func (lexer *Lexer) scanHex(limit int) string {
+	start := lexer.pos
+	for lexer.pos-start < limit && isHexDigit(lexer.currentChar) {
+		lexer.Advance()
+	}
+	return lexer.sourceCode[start:lexer.pos]
+}
+
+func hexValue(digits string) rune {
+	var value rune
+	for i := 0; i < len(digits); i++ {
+		c := digits[i]
+		switch {
+		case isDigit(c):
+			value = value*16 + rune(c-'0')
+		case c >= 'a':
+			value = value*16 + rune(c-'a'+10)
+		default:
+			value = value*16 + rune(c-'A'+10)
+		}
+	}
+	return value
+}
@@ -1,7 +1,10 @@
 // token.go
 package lexer
 
-import "fmt"
+import (
+	"fmt"
+	"pcl/src/frontend/source"
+)
 
 type TokenType int
 
@@ -10,6 +13,9 @@ const (
 	IdentifierToken TokenType = iota
 	NumberToken
 	StringToken
+	StringHeadToken   // "text ${   an interpolated string up to its first expression
+	StringMiddleToken // } text ${ between two expressions
+	StringTailToken   // } text"    after the last one
 
 	// operators
 	PlusToken
@@ -17,6 +23,8 @@ const (
 	StarToken
 	SlashToken
 	PercentToken
+	PowerToken
+	FloorDivToken
 	EqualToken
 	DoubleEqualToken
 	NotEqualToken
@@ -27,9 +35,22 @@ const (
 	LogicalOrToken
 	LogicalAndToken
 	LogicalNotToken
+	NilCoalesceToken
 	BitwiseOrToken
 	BitwiseAndToken
 	BitwiseNotToken
+	BitwiseXorToken
+	ShiftLeftToken
+	ShiftRightToken
+
+	// assignment operators
+	PlusEqualToken
+	MinusEqualToken
+	StarEqualToken
+	SlashEqualToken
+	PercentEqualToken
+	IncrementToken
+	DecrementToken
 
 	// punctuation
 	LParenToken
@@ -40,27 +61,51 @@ const (
 	RBracketToken
 	SemicolonToken
 	CommaToken
+	ColonToken
 	DotToken
+	QuestionToken
+	OptionalDotToken
+	RangeToken
+	RangeInclusiveToken
+	EllipsisToken
+	FatArrowToken
 
 	// keywords
 	VarToken
 	IfToken
 	ElseToken
 	ForToken
+	InToken
 	WhileToken
 	FuncToken
 	ReturnToken
+	DeleteToken
+	BreakToken
+	ContinueToken
+	TryToken
+	CatchToken
+	FinallyToken
+	ThrowToken
+	StructToken
+	ClassToken
+	ExtendsToken
+	ThisToken
+	SuperToken
+	EnumToken
+	MatchToken
 
 	// whitespace/comments
 	CommentToken
 
 	// special
+	ErrorToken // source the lexer could not make sense of, Tokenize returns a diagnostic for it
 	EOFToken
 )
 
 type Token struct {
 	Type  TokenType
 	Value string
+	Span  source.Span
 }
 
 func (token TokenType) String() string {
@@ -69,6 +114,9 @@ func (token TokenType) String() string {
 		"IdentifierToken",
 		"NumberToken",
 		"StringToken",
+		"StringHeadToken",
+		"StringMiddleToken",
+		"StringTailToken",
 
 		// operators
 		"PlusToken",
@@ -76,6 +124,8 @@ func (token TokenType) String() string {
 		"StarToken",
 		"SlashToken",
 		"PercentToken",
+		"PowerToken",
+		"FloorDivToken",
 		"EqualToken",
 		"DoubleEqualToken",
 		"NotEqualToken",
@@ -86,9 +136,22 @@ func (token TokenType) String() string {
 		"LogicalOrToken",
 		"LogicalAndToken",
 		"LogicalNotToken",
+		"NilCoalesceToken",
 		"BitwiseOrToken",
 		"BitwiseAndToken",
 		"BitwiseNotToken",
+		"BitwiseXorToken",
+		"ShiftLeftToken",
+		"ShiftRightToken",
+
+		// assignment operators
+		"PlusEqualToken",
+		"MinusEqualToken",
+		"StarEqualToken",
+		"SlashEqualToken",
+		"PercentEqualToken",
+		"IncrementToken",
+		"DecrementToken",
 
 		// punctuation
 		"LParenToken",
@@ -99,21 +162,44 @@ func (token TokenType) String() string {
 		"RBracketToken",
 		"SemicolonToken",
 		"CommaToken",
+		"ColonToken",
 		"DotToken",
+		"QuestionToken",
+		"OptionalDotToken",
+		"RangeToken",
+		"RangeInclusiveToken",
+		"EllipsisToken",
+		"FatArrowToken",
 
 		// keywords
 		"VarToken",
 		"IfToken",
 		"ElseToken",
 		"ForToken",
+		"InToken",
 		"WhileToken",
 		"FuncToken",
 		"ReturnToken",
+		"DeleteToken",
+		"BreakToken",
+		"ContinueToken",
+		"TryToken",
+		"CatchToken",
+		"FinallyToken",
+		"ThrowToken",
+		"StructToken",
+		"ClassToken",
+		"ExtendsToken",
+		"ThisToken",
+		"SuperToken",
+		"EnumToken",
+		"MatchToken",
 
 		// whitespace/comments
 		"CommentToken",
 
 		// special
+		"ErrorToken",
 		"EOFToken",
 	}
 
@@ -125,5 +211,5 @@ func (token TokenType) String() string {
 }
 
 func (token Token) String() string {
-	return fmt.Sprintf("Token { Type: %-15s | Value: '%s' }", token.Type, token.Value)
+	return fmt.Sprintf("Token { Type: %-15s | Value: '%s' | Pos: %s }", token.Type, token.Value, token.Span)
 }
@@ -5,21 +5,135 @@ import (
 	"pcl/src/frontend/lexer"
 )
 
-func (parser *Parser) parseAssignment() ast.ASTNode {
-	nameToken := parser.expect(lexer.IdentifierToken, "expected identifier at start of assignment")
-	parser.expect(lexer.EqualToken, "expected '=' after identifier")
+func isAssignmentOperator(tType lexer.TokenType) bool {
+	switch tType {
+	case lexer.EqualToken, lexer.PlusEqualToken, lexer.MinusEqualToken,
+		lexer.StarEqualToken, lexer.SlashEqualToken, lexer.PercentEqualToken,
+		lexer.IncrementToken, lexer.DecrementToken:
+		return true
+	default:
+		return false
+	}
+}
+
+// parses an expression and, when an assignment operator follows it, turns it
+// into an assignment with that expression as the target
+func (parser *Parser) parseAssignmentOrExpression() ast.ASTNode {
+	// prefix form: ++x / --x
+	if op := parser.peek(); op.Type == lexer.IncrementToken || op.Type == lexer.DecrementToken {
+		parser.eat()
+		target := parser.assignmentTarget(parser.parsePostfix())
+		return stepAssignment(target, op, parser.posFrom(op))
+	}
+
+	expr := parser.parseExpression()
+
+	if t := parser.peek(); t != nil && isAssignmentOperator(t.Type) {
+		return parser.parseAssignment(expr)
+	}
+
+	if t := parser.peek(); t != nil && t.Type == lexer.CommaToken {
+		return parser.parseParallelAssignment(expr)
+	}
+
+	return expr
+}
+
+// a, b = b, a, with the first target already parsed
+func (parser *Parser) parseParallelAssignment(first ast.ASTNode) ast.ASTNode {
+	targets := []ast.ASTNode{parser.assignmentTarget(first)}
+	for t := parser.peek(); t != nil && t.Type == lexer.CommaToken; t = parser.peek() {
+		parser.eat() // eat ','
+		targets = append(targets, parser.assignmentTarget(parser.parseExpression()))
+	}
+
+	parser.expect(lexer.EqualToken, "expected '=' after assignment targets")
+
+	values := []ast.ASTNode{parser.parseExpression()}
+	for t := parser.peek(); t != nil && t.Type == lexer.CommaToken; t = parser.peek() {
+		parser.eat() // eat ','
+		values = append(values, parser.parseExpression())
+	}
+
+	last := values[len(values)-1]
+	if len(values) != 1 && len(values) != len(targets) {
+		parser.errorAt(posBetween(values[0], last).Span, "expected %d values to assign, got %d", len(targets), len(values))
+	}
+
+	return &ast.ParallelAssignmentNode{Pos: posBetween(first, last), Targets: targets, Values: values}
+}
+
+func (parser *Parser) parseAssignment(target ast.ASTNode) ast.ASTNode {
+	target = parser.assignmentTarget(target)
+	opToken := parser.eat()
+
+	if opToken.Type == lexer.IncrementToken || opToken.Type == lexer.DecrementToken {
+		return stepAssignment(target, opToken, ast.Pos{Span: target.Position().To(opToken.Span)})
+	}
 
+	// the trailing ';' belongs to the caller, so for-loop updates can reuse this
 	value := parser.parseExpression()
-	parser.expect(lexer.SemicolonToken, "expected ';' after expression")
 
 	return &ast.AssignmentNode{
-		Name:  nameToken.Value,
-		Value: value,
+		Pos:      posBetween(target, value),
+		Target:   target,
+		Operator: opToken.Value,
+		Value:    value,
 	}
 }
 
+func (parser *Parser) assignmentTarget(target ast.ASTNode) ast.ASTNode {
+	switch target := target.(type) {
+	case *ast.OptionalChainNode:
+		parser.errorAt(target.Position(), "cannot assign through '?.'")
+		return nil
+	case *ast.IdentifierNode, *ast.IndexNode, *ast.MemberNode:
+		return target
+	default:
+		parser.errorAt(target.Position(), "invalid assignment target")
+		return nil
+	}
+}
+
+// x++ is just x += 1, the implicit 1 points at the operator
+func stepAssignment(target ast.ASTNode, op *lexer.Token, pos ast.Pos) ast.ASTNode {
+	operator := "+="
+	if op.Type == lexer.DecrementToken {
+		operator = "-="
+	}
+
+	return &ast.AssignmentNode{
+		Pos:      pos,
+		Target:   target,
+		Operator: operator,
+		Value:    &ast.LiteralNode[int]{Pos: ast.Pos{Span: op.Span}, Value: 1},
+	}
+}
+
+func (parser *Parser) parseDeleteStatement() ast.ASTNode {
+	start := parser.expect(lexer.DeleteToken, "expected 'delete'")
+
+	target, ok := parser.parsePostfix().(*ast.IndexNode)
+	if !ok {
+		parser.errorAtToken(start, "expected an indexed target like m[k] after 'delete'")
+	}
+
+	parser.expect(lexer.SemicolonToken, "expected ';' after delete")
+	return &ast.DeleteNode{Pos: parser.posFrom(start), Target: target}
+}
+
 func (parser *Parser) parseVarDecl() ast.ASTNode {
-	parser.eat() // eat 'var'
+	start := parser.eat() // eat 'var'
+
+	// var [a, b] = xs / var {a, b} = m
+	if t := parser.peek(); t != nil && (t.Type == lexer.LBracketToken || t.Type == lexer.LBraceToken) {
+		pattern := parser.parsePattern()
+		parser.expect(lexer.EqualToken, "expected '=' after destructuring pattern")
+		value := parser.parseExpression()
+		parser.expect(lexer.SemicolonToken, "expected ';' after expression")
+		return &ast.VarDeclNode{Pos: parser.posFrom(start), Pattern: pattern, Value: value}
+	}
+
 	name := parser.expect(lexer.IdentifierToken, "expected identifier after 'var'")
 
 	currentToken := parser.peek()
@@ -28,27 +142,34 @@ func (parser *Parser) parseVarDecl() ast.ASTNode {
 		parser.eat() // eat '='
 		value := parser.parseExpression()
 		parser.expect(lexer.SemicolonToken, "expected ';' after expression")
-		return &ast.VarDeclNode{Name: name.Value, Value: value}
+
+		// var f = func(...) {...} names the function after its variable
+		if literal, ok := value.(*ast.FunctionLiteralNode); ok && literal.Name == "" {
+			literal.Name = name.Value
+		}
+		return &ast.VarDeclNode{Pos: parser.posFrom(start), Name: name.Value, Value: value}
 
 	case lexer.SemicolonToken:
 		parser.expect(lexer.SemicolonToken, "expected ';' after variable declaration")
-		return &ast.VarDeclNode{Name: name.Value, Value: nil}
+		return &ast.VarDeclNode{Pos: parser.posFrom(start), Name: name.Value, Value: nil}
 	}
 
-	panic("unexpected token: " + currentToken.String())
+	parser.errorAtToken(currentToken, "expected '=' or ';' after variable name, found %s", describe(currentToken))
+	return nil
 }
 
 func (parser *Parser) parseFuncDecl() ast.ASTNode {
-    parser.eat() // eat 'func'
-    name := parser.expect(lexer.IdentifierToken, "expected function name after 'func'")
+	start := parser.eat() // eat 'func'
+	name := parser.expect(lexer.IdentifierToken, "expected function name after 'func'")
 
-    // parse the literal starting from '('
-    literal := parser.parseFunctionLiteral()
+	// parse the literal starting from '('
+	literal := parser.parseFunctionSignature(start)
+	literal.Name = name.Value
 
-    // wrap it inside a var decl node
-    return &ast.VarDeclNode{
-        Name:  name.Value,
-        Value: literal,
-    }
+	// wrap it inside a var decl node
+	return &ast.VarDeclNode{
+		Pos:   parser.posFrom(start),
+		Name:  name.Value,
+		Value: literal,
+	}
 }
-
@@ -0,0 +1,34 @@
+package parser
+
+import (
+	"pcl/src/frontend/ast"
+	"pcl/src/frontend/lexer"
+)
+
+func (parser *Parser) parseIfStatement() ast.ASTNode {
+	start := parser.expect(lexer.IfToken, "expected 'if'")
+	parser.expect(lexer.LParenToken, "expected '(' after 'if'")
+
+	condition := parser.parseExpression()
+
+	parser.expect(lexer.RParenToken, "expected ')' after if condition")
+
+	ifNode := &ast.IfNode{
+		Condition:  condition,
+		Consequent: parser.parseBody(),
+	}
+
+	if parser.peek() != nil && parser.peek().Type == lexer.ElseToken {
+		parser.eat() // eat 'else'
+
+		// 'else if' chains become a nested IfNode in the alternate slot
+		if parser.peek() != nil && parser.peek().Type == lexer.IfToken {
+			ifNode.Alternate = parser.parseIfStatement()
+		} else {
+			ifNode.Alternate = parser.parseBody()
+		}
+	}
+
+	ifNode.Pos = parser.posFrom(start)
+	return ifNode
+}
@@ -0,0 +1,42 @@
+package parser
+
+import (
+	"pcl/src/frontend/ast"
+	"pcl/src/frontend/lexer"
+)
+
+func (parser *Parser) parseTryStatement() ast.ASTNode {
+	start := parser.expect(lexer.TryToken, "expected 'try'")
+
+	tryNode := &ast.TryNode{Body: parser.parseBody()}
+
+	if parser.peek() != nil && parser.peek().Type == lexer.CatchToken {
+		parser.eat() // eat 'catch'
+		parser.expect(lexer.LParenToken, "expected '(' after 'catch'")
+		name := parser.expect(lexer.IdentifierToken, "expected a name for the caught error")
+		parser.expect(lexer.RParenToken, "expected ')' after catch variable")
+
+		tryNode.CatchName = name.Value
+		tryNode.Catch = parser.parseBody()
+	}
+
+	if parser.peek() != nil && parser.peek().Type == lexer.FinallyToken {
+		parser.eat() // eat 'finally'
+		tryNode.Finally = parser.parseBody()
+	}
+
+	if tryNode.Catch == nil && tryNode.Finally == nil {
+		parser.errorAtToken(parser.peek(), "expected 'catch' or 'finally' after try block, found %s", describe(parser.peek()))
+	}
+
+	tryNode.Pos = parser.posFrom(start)
+	return tryNode
+}
+
+func (parser *Parser) parseThrowStatement() ast.ASTNode {
+	start := parser.expect(lexer.ThrowToken, "expected 'throw'")
+	value := parser.parseExpression()
+	parser.expect(lexer.SemicolonToken, "expected ';' after throw")
+
+	return &ast.ThrowNode{Pos: parser.posFrom(start), Value: value}
+}
@@ -1,106 +1,251 @@
 package parser
 
 import (
+	"fmt"
+	"math/big"
 	"pcl/src/frontend/ast"
 	"pcl/src/frontend/lexer"
-	"strconv"
-	"strings"
 )
 
+// binding power of each binary operator, higher binds tighter. Prefix
+// operators sit between multiplicative and '**' so -2 ** 2 is -(2 ** 2).
+const (
+	precedenceLowest = iota
+	precedenceNilCoalesce
+	precedenceOr
+	precedenceAnd
+	precedenceBitwiseOr
+	precedenceBitwiseXor
+	precedenceBitwiseAnd
+	precedenceEquality
+	precedenceRelational
+	precedenceShift
+	precedenceAdditive
+	precedenceMultiplicative
+	precedenceUnary
+	precedencePower
+)
+
+var binaryPrecedence = map[lexer.TokenType]int{
+	lexer.NilCoalesceToken:  precedenceNilCoalesce,
+	lexer.LogicalOrToken:    precedenceOr,
+	lexer.LogicalAndToken:   precedenceAnd,
+	lexer.BitwiseOrToken:    precedenceBitwiseOr,
+	lexer.BitwiseXorToken:   precedenceBitwiseXor,
+	lexer.BitwiseAndToken:   precedenceBitwiseAnd,
+	lexer.DoubleEqualToken:  precedenceEquality,
+	lexer.NotEqualToken:     precedenceEquality,
+	lexer.LessThanToken:     precedenceRelational,
+	lexer.LessEqualToken:    precedenceRelational,
+	lexer.GreaterThanToken:  precedenceRelational,
+	lexer.GreaterEqualToken: precedenceRelational,
+	lexer.InToken:           precedenceRelational,
+	lexer.ShiftLeftToken:    precedenceShift,
+	lexer.ShiftRightToken:   precedenceShift,
+	lexer.PlusToken:         precedenceAdditive,
+	lexer.MinusToken:        precedenceAdditive,
+	lexer.StarToken:         precedenceMultiplicative,
+	lexer.SlashToken:        precedenceMultiplicative,
+	lexer.PercentToken:      precedenceMultiplicative,
+	lexer.FloorDivToken:     precedenceMultiplicative,
+	lexer.PowerToken:        precedencePower,
+}
+
+// 2 ** 3 ** 2 is 2 ** (3 ** 2), everything else groups to the left
+var rightAssociative = map[lexer.TokenType]bool{
+	lexer.PowerToken: true,
+}
+
+var prefixOperators = map[lexer.TokenType]bool{
+	lexer.LogicalNotToken: true,
+	lexer.BitwiseNotToken: true,
+	lexer.MinusToken:      true,
+	lexer.PlusToken:       true,
+}
+
 func (parser *Parser) parseExpression() ast.ASTNode {
-	return parser.parseComparison()
+	return parser.parseTernary()
 }
 
-func (parser *Parser) parseComparison() ast.ASTNode {
-	left := parser.parseAdditive()
+// cond ? a : b binds loosest and nests to the right, so a ? b : c ? d : e
+// reads as a ? b : (c ? d : e)
+func (parser *Parser) parseTernary() ast.ASTNode {
+	condition := parser.parseBinary(precedenceLowest + 1)
 
-	for {
-		token := parser.peek()
-		if token == nil {
-			break
-		}
+	if parser.peek() == nil || parser.peek().Type != lexer.QuestionToken {
+		return condition
+	}
+	parser.eat() // eat '?'
 
-		if token.Type == lexer.LessThanToken || token.Type == lexer.GreaterThanToken ||
-			token.Type == lexer.DoubleEqualToken || token.Type == lexer.NotEqualToken ||
-			token.Type == lexer.LessEqualToken || token.Type == lexer.GreaterEqualToken ||
-			token.Type == lexer.LogicalAndToken || token.Type == lexer.LogicalOrToken {
+	consequent := parser.parseTernary()
+	parser.expect(lexer.ColonToken, "expected ':' in conditional expression")
+	alternate := parser.parseTernary()
 
-			parser.eat()
-			right := parser.parseAdditive()
+	return &ast.TernaryNode{
+		Pos:        posBetween(condition, alternate),
+		Condition:  condition,
+		Consequent: consequent,
+		Alternate:  alternate,
+	}
+}
 
-			left = &ast.BinaryOpNode{
-				Left:     left,
-				Operator: token.Value,
-				Right:    right,
-			}
-		} else {
+// parseBinary is precedence climbing: it parses an operand, then folds in
+// operators for as long as they bind at least as tightly as minPrecedence
+func (parser *Parser) parseBinary(minPrecedence int) ast.ASTNode {
+	left := parser.parseUnary()
+
+	for token := parser.peek(); token != nil; token = parser.peek() {
+		precedence, ok := binaryPrecedence[token.Type]
+		if !ok || precedence < minPrecedence {
 			break
 		}
+		parser.eat()
+
+		next := precedence + 1
+		if rightAssociative[token.Type] {
+			next = precedence
+		}
+		right := parser.parseBinary(next)
+
+		left = &ast.BinaryOpNode{
+			Pos:      posBetween(left, right),
+			Left:     left,
+			Operator: token.Value,
+			Right:    right,
+		}
 	}
 
 	return left
 }
 
-func (parser *Parser) parseAdditive() ast.ASTNode {
-	left := parser.parseMultiplicative()
+func (parser *Parser) parseUnary() ast.ASTNode {
+	token := parser.peek()
+	if token == nil {
+		parser.errorAtToken(token, "unexpected end of input in expression")
+	}
 
-	for {
-		token := parser.peek()
-		if token == nil {
-			break
+	if prefixOperators[token.Type] {
+		parser.eat()
+		operand := parser.parseBinary(precedencePower)
+		return &ast.UnaryOpNode{Pos: parser.posFrom(token), Operator: token.Value, Operand: operand}
+	}
+
+	return parser.parsePostfix()
+}
+
+// primary followed by any chain of (args), [index], [start:end], .member and
+// .method(args), plus the nil-safe forms ?.member, ?.method(args) and ?.(args).
+// A chain using any of those is wrapped in an OptionalChainNode.
+func (parser *Parser) parsePostfix() ast.ASTNode {
+	expr := parser.parsePrimary()
+	optional := false
+
+	for t := parser.peek(); t != nil; t = parser.peek() {
+		if t.Type == lexer.OptionalDotToken {
+			optional = true
 		}
 
-		if token.Type == lexer.PlusToken || token.Type == lexer.MinusToken {
-			parser.eat()
-			right := parser.parseMultiplicative()
-			left = &ast.BinaryOpNode{Left: left, Operator: token.Value, Right: right}
-		} else {
-			break
+		switch t.Type {
+		case lexer.LParenToken:
+			arguments := parser.parseArguments()
+			expr = &ast.FunctionCallNode{
+				Pos:       ast.Pos{Span: expr.Position().To(parser.previous().Span)},
+				Callee:    expr,
+				Arguments: arguments,
+			}
+		case lexer.LBracketToken:
+			expr = parser.parseIndex(expr)
+		case lexer.OptionalDotToken:
+			if next := parser.peekAhead(1); next != nil && next.Type == lexer.LParenToken {
+				parser.eat() // eat '?.'
+				arguments := parser.parseArguments()
+				expr = &ast.FunctionCallNode{
+					Pos:       ast.Pos{Span: expr.Position().To(parser.previous().Span)},
+					Callee:    expr,
+					Arguments: arguments,
+					Optional:  true,
+				}
+				continue
+			}
+			expr = parser.parseMember(expr)
+		case lexer.DotToken:
+			expr = parser.parseMember(expr)
+		default:
+			return optionalChain(expr, optional)
 		}
 	}
 
-	return left
+	return optionalChain(expr, optional)
 }
 
-func (parser *Parser) parseMultiplicative() ast.ASTNode {
-	left := parser.parseUnary()
+func optionalChain(expr ast.ASTNode, optional bool) ast.ASTNode {
+	if !optional {
+		return expr
+	}
+	return &ast.OptionalChainNode{Pos: ast.Pos{Span: expr.Position()}, Chain: expr}
+}
 
-	for {
-		token := parser.peek()
-		if token == nil {
-			break
-		}
+func (parser *Parser) parseMember(object ast.ASTNode) ast.ASTNode {
+	dot := parser.eat() // eat '.' or '?.'
+	optional := dot.Type == lexer.OptionalDotToken
+	name := parser.expect(lexer.IdentifierToken, "expected property name after '"+dot.Value+"'")
 
-		if token.Type == lexer.StarToken || token.Type == lexer.SlashToken || token.Type == lexer.PercentToken {
-			parser.eat()
-			right := parser.parseUnary()
-			left = &ast.BinaryOpNode{Left: left, Operator: token.Value, Right: right}
-		} else {
-			break
+	if parser.peek() != nil && parser.peek().Type == lexer.LParenToken {
+		arguments := parser.parseArguments()
+		return &ast.MethodCallNode{
+			Pos:       ast.Pos{Span: object.Position().To(parser.previous().Span)},
+			Object:    object,
+			Method:    name.Value,
+			Arguments: arguments,
+			Optional:  optional,
 		}
 	}
 
-	return left
+	return &ast.MemberNode{
+		Pos:      ast.Pos{Span: object.Position().To(name.Span)},
+		Object:   object,
+		Property: name.Value,
+		Optional: optional,
+	}
 }
 
-func (parser *Parser) parseUnary() ast.ASTNode {
-	token := parser.peek()
-	if token == nil {
-		panic("unexpected end of input in unary")
+func (parser *Parser) parseIndex(object ast.ASTNode) ast.ASTNode {
+	parser.expect(lexer.LBracketToken, "expected '['")
+
+	var start ast.ASTNode
+	if parser.peek().Type != lexer.ColonToken {
+		start = parser.parseExpression()
 	}
 
-	if token.Type == lexer.PlusToken || token.Type == lexer.MinusToken {
-		parser.eat()
-		return &ast.UnaryOpNode{Operator: token.Value, Operand: parser.parseUnary()}
+	if parser.peek().Type != lexer.ColonToken {
+		closing := parser.expect(lexer.RBracketToken, "expected ']' after index")
+		return &ast.IndexNode{
+			Pos:    ast.Pos{Span: object.Position().To(closing.Span)},
+			Object: object,
+			Index:  start,
+		}
+	}
+
+	parser.eat() // eat ':'
+
+	var end ast.ASTNode
+	if parser.peek().Type != lexer.RBracketToken {
+		end = parser.parseExpression()
 	}
 
-	return parser.parsePrimary()
+	closing := parser.expect(lexer.RBracketToken, "expected ']' after slice")
+	return &ast.SliceNode{
+		Pos:    ast.Pos{Span: object.Position().To(closing.Span)},
+		Object: object,
+		Start:  start,
+		End:    end,
+	}
 }
 
 func (parser *Parser) parsePrimary() ast.ASTNode {
 	token := parser.peek()
 	if token == nil {
-		panic("unexpected end of input in term")
+		parser.errorAtToken(token, "unexpected end of input in expression")
 	}
 
 	switch token.Type {
@@ -111,23 +256,29 @@ func (parser *Parser) parsePrimary() ast.ASTNode {
 	case lexer.NumberToken:
 		parser.eat()
 		val := token.Value
-		if strings.Contains(val, ".") || strings.ContainsAny(val, "eE") {
-			num, err := strconv.ParseFloat(val, 64)
-			if err != nil {
-				panic("invalid float literal: " + val)
+		if lexer.IsFloatLiteral(val) {
+			num, ok := lexer.ParseFloat(val)
+			if !ok {
+				parser.errorAtToken(token, "invalid float literal: %s", val)
 			}
-			return &ast.LiteralNode[float64]{Value: num}
+			return &ast.LiteralNode[float64]{Pos: ast.Pos{Span: token.Span}, Value: num}
 		} else {
-			num, err := strconv.Atoi(val)
-			if err != nil {
-				panic("invalid int literal: " + val)
+			num, ok := lexer.ParseInt(val)
+			if !ok {
+				parser.errorAtToken(token, "invalid int literal: %s", val)
+			}
+			if num.IsInt64() && int64(int(num.Int64())) == num.Int64() {
+				return &ast.LiteralNode[int]{Pos: ast.Pos{Span: token.Span}, Value: int(num.Int64())}
 			}
-			return &ast.LiteralNode[int]{Value: num}
+			return &ast.LiteralNode[*big.Int]{Pos: ast.Pos{Span: token.Span}, Value: num}
 		}
 
 	case lexer.StringToken:
 		parser.eat()
-		return &ast.LiteralNode[string]{Value: token.Value}
+		return &ast.LiteralNode[string]{Pos: ast.Pos{Span: token.Span}, Value: token.Value}
+
+	case lexer.StringHeadToken:
+		return parser.parseInterpolation()
 
 	case lexer.LParenToken:
 		parser.eat()
@@ -135,11 +286,33 @@ func (parser *Parser) parsePrimary() ast.ASTNode {
 		parser.expect(lexer.RParenToken, "expected ')' after expression")
 		return expr
 
+	case lexer.LBracketToken:
+		return parser.parseArrayLiteral()
+
+	case lexer.LBraceToken:
+		// in expression position a brace always opens a map, blocks only start statements
+		return parser.parseMapLiteral()
+
 	case lexer.IdentifierToken:
-		return parser.parseFunctionCall()
+		return parser.parseIdentifier()
+
+	case lexer.MatchToken:
+		return parser.parseMatch()
+
+	case lexer.ThisToken:
+		parser.eat()
+		return &ast.ThisNode{Pos: ast.Pos{Span: token.Span}}
+
+	case lexer.SuperToken:
+		// super on its own means nothing, it is always super.method
+		parser.eat()
+		parser.expect(lexer.DotToken, "expected '.' after 'super'")
+		method := parser.expect(lexer.IdentifierToken, "expected method name after 'super.'")
+		return &ast.SuperNode{Pos: parser.posFrom(token), Method: method.Value}
 
 	default:
-		panic("unknown term: " + token.Value)
+		parser.errorAtToken(token, "expected an expression, found %s", describe(token))
+		return nil
 	}
 }
 
@@ -147,68 +320,153 @@ func (parser *Parser) parsePrimary() ast.ASTNode {
 
 func (parser *Parser) parseIdentifier() *ast.IdentifierNode {
 	identToken := parser.eat() // eat identifier
-	return &ast.IdentifierNode{Name: identToken.Value}
+	return &ast.IdentifierNode{Pos: ast.Pos{Span: identToken.Span}, Name: identToken.Value}
 }
 
-// ---------- Function Call ----------
+// ---------- Array Literal ----------
 
-func (parser *Parser) parseFunctionCall() ast.ASTNode {
-	identifier := parser.parseIdentifier()
+func (parser *Parser) parseArrayLiteral() ast.ASTNode {
+	start := parser.expect(lexer.LBracketToken, "expected '['")
 
-	if parser.peek() != nil && parser.peek().Type == lexer.LParenToken {
-		parser.eat() // eat '('
+	array := &ast.ArrayLiteralNode{Elements: []ast.ASTNode{}}
+
+	for t := parser.peek(); t != nil && t.Type != lexer.RBracketToken; t = parser.peek() {
+		array.Elements = append(array.Elements, parser.parseExpression())
 
-		functionCallNode := &ast.FunctionCallNode{
-			Callee:    identifier,
-			Arguments: []ast.ASTNode{},
+		if parser.peek().Type != lexer.CommaToken {
+			break
 		}
+		parser.eat() // eat ','
+	}
 
-		for parser.peek() != nil && parser.peek().Type != lexer.RParenToken {
-			arg := parser.parseExpression()
-			functionCallNode.Arguments = append(functionCallNode.Arguments, arg)
+	parser.expect(lexer.RBracketToken, "expected ']' after array elements")
+	array.Pos = parser.posFrom(start)
+	return array
+}
 
-			if parser.peek() != nil && parser.peek().Type == lexer.CommaToken {
-				parser.eat() // eat ','
-			}
+// ---------- Map Literal ----------
+
+func (parser *Parser) parseMapLiteral() ast.ASTNode {
+	start := parser.expect(lexer.LBraceToken, "expected '{'")
+
+	mapNode := &ast.MapLiteralNode{Keys: []ast.ASTNode{}, Values: []ast.ASTNode{}}
+
+	for t := parser.peek(); t != nil && t.Type != lexer.RBraceToken; t = parser.peek() {
+		key := parser.parseExpression()
+		parser.expect(lexer.ColonToken, "expected ':' after map key")
+		value := parser.parseExpression()
+
+		mapNode.Keys = append(mapNode.Keys, key)
+		mapNode.Values = append(mapNode.Values, value)
+
+		if parser.peek().Type != lexer.CommaToken {
+			break
 		}
+		parser.eat() // eat ','
+	}
 
-		parser.expect(lexer.RParenToken, "expected ')' after arguments")
+	parser.expect(lexer.RBraceToken, "expected '}' after map entries")
+	mapNode.Pos = parser.posFrom(start)
+	return mapNode
+}
+
+// ---------- Function Call ----------
 
-		return functionCallNode
+// parses '(' [expr {',' expr}] ')'
+func (parser *Parser) parseArguments() []ast.ASTNode {
+	parser.expect(lexer.LParenToken, "expected '('")
+
+	arguments := []ast.ASTNode{}
+
+	for parser.peek() != nil && parser.peek().Type != lexer.RParenToken {
+		arguments = append(arguments, parser.parseExpression())
+
+		if parser.peek() == nil || parser.peek().Type != lexer.CommaToken {
+			break
+		}
+		parser.eat() // eat ','
 	}
 
-	return identifier
+	parser.expect(lexer.RParenToken, "expected ')' after arguments")
+
+	return arguments
 }
 
 func (parser *Parser) parseFunctionLiteral() ast.ASTNode {
-	parser.expect(lexer.FuncToken, "expected 'func'")
+	start := parser.expect(lexer.FuncToken, "expected 'func'")
+	return parser.parseFunctionSignature(start)
+}
 
+// parses everything after 'func' (and the name, for declarations)
+func (parser *Parser) parseFunctionSignature(start *lexer.Token) *ast.FunctionLiteralNode {
 	parser.expect(lexer.LParenToken, "expected '(' after func")
 
 	var params []string
+	var destructure []ast.ASTNode
 	for t := parser.peek(); t != nil && t.Type != lexer.RParenToken; t = parser.peek() {
-		paramName := parser.expect(lexer.IdentifierToken, "expected parameter name")
-		params = append(params, paramName.Value)
-
-		if parser.peek().Type == lexer.CommaToken {
-			parser.eat()
+		// func f([x, y], {name}) takes the argument under a name no script can
+		// spell and destructures it first thing in the body
+		if t.Type == lexer.LBracketToken || t.Type == lexer.LBraceToken {
+			pattern := parser.parsePattern()
+			hidden := fmt.Sprintf("<argument %d>", len(params)+1)
+			params = append(params, hidden)
+			destructure = append(destructure, &ast.VarDeclNode{
+				Pos:     ast.Pos{Span: pattern.Position()},
+				Pattern: pattern,
+				Value:   &ast.IdentifierNode{Pos: ast.Pos{Span: pattern.Position()}, Name: hidden},
+			})
+		} else {
+			paramName := parser.expect(lexer.IdentifierToken, "expected parameter name")
+			params = append(params, paramName.Value)
 		}
 
+		if parser.peek().Type != lexer.CommaToken {
+			break
+		}
+		parser.eat() // eat ','
 	}
 
 	parser.expect(lexer.RParenToken, "expected ')' after parameters")
-	parser.expect(lexer.LBraceToken, "expected '{' before function body")
-
-	var body []ast.ASTNode
 
-	for t := parser.peek(); t != nil && t.Type != lexer.RBraceToken; t = parser.peek() {
-		body = append(body, parser.parseStatement())
+	body := parser.parseBody()
+	if destructure != nil {
+		body.Statements = append(destructure, body.Statements...)
 	}
 
-	parser.expect(lexer.RBraceToken, "expected '}' after function body")
-
 	return &ast.FunctionLiteralNode{
+		Pos:       parser.posFrom(start),
 		Arguments: params,
-		Body:      &ast.BodyNode{Statements: body},
+		Body:      body,
 	}
 }
+
+// the lexer splits "a ${x} b ${y} c" into StringHead("a "), x,
+// StringMiddle(" b "), y, StringTail(" c")
+func (parser *Parser) parseInterpolation() ast.ASTNode {
+	head := parser.expect(lexer.StringHeadToken, "expected a string")
+
+	var parts []ast.ASTNode
+	text := func(t *lexer.Token) {
+		if t.Value != "" {
+			parts = append(parts, &ast.LiteralNode[string]{Pos: ast.Pos{Span: t.Span}, Value: t.Value})
+		}
+	}
+	text(head)
+
+	for {
+		parts = append(parts, parser.parseExpression())
+
+		t := parser.peek()
+		if t == nil || (t.Type != lexer.StringMiddleToken && t.Type != lexer.StringTailToken) {
+			parser.errorAtToken(t, "expected '}' after interpolated expression, found %s", describe(t))
+		}
+		parser.eat()
+		text(t)
+
+		if t.Type == lexer.StringTailToken {
+			break
+		}
+	}
+
+	return &ast.InterpolationNode{Pos: parser.posFrom(head), Parts: parts}
+}
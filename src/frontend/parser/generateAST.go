@@ -3,22 +3,120 @@ package parser
 import (
 	"pcl/src/frontend/ast"
 	"pcl/src/frontend/lexer"
+	"pcl/src/frontend/source"
 )
 
-func (parser *Parser) GenerateAST() ast.ASTNode {
+// GenerateAST parses the whole token stream. Syntax errors don't stop it: every
+// one of them is returned as a diagnostic alongside whatever AST could be built,
+// and the AST should not be run unless the diagnostics are empty.
+func (parser *Parser) GenerateAST() (ast.ASTNode, []source.Diagnostic) {
 	program := &ast.ProgramNode{Statements: []ast.ASTNode{}}
 
 	for t := parser.peek(); t != nil && t.Type != lexer.EOFToken; t = parser.peek() {
-		program.Statements = append(program.Statements, parser.parseStatement())
+		if stmt := parser.parseStatementRecovering(); stmt != nil {
+			program.Statements = append(program.Statements, stmt)
+		}
+	}
+
+	if len(parser.tokens) > 0 {
+		program.Pos = ast.Pos{Span: parser.tokens[0].Span.To(parser.tokens[len(parser.tokens)-1].Span)}
+	}
+
+	return program, parser.diagnostics
+}
+
+// parseStatementRecovering parses one statement; on a syntax error it records the
+// diagnostic, skips to the next ';' or '}' and returns nil
+func (parser *Parser) parseStatementRecovering() (stmt ast.ASTNode) {
+	start := parser.pos
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		err, ok := r.(syntaxError)
+		if !ok {
+			panic(r)
+		}
+
+		if !parser.brokenByLexer(start) {
+			parser.diagnostics = append(parser.diagnostics, err.diagnostic)
+		}
+		parser.synchronize(start)
+		stmt = nil
+	}()
+
+	return parser.parseStatement()
+}
+
+// brokenByLexer reports whether the statement that failed from start had an
+// ErrorToken dropped from it; the lexer already reported that, and whatever
+// the parser tripped over after it is only a consequence
+func (parser *Parser) brokenByLexer(start int) bool {
+	for i := start; i <= parser.pos; i++ {
+		if parser.afterError[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// synchronize skips past the next ';' or past a whole '{ ... }' block, or up to
+// (not over) an unmatched '}' so the enclosing block can still close itself.
+// Braces the failed statement already opened count as open, so an error
+// inside match { ... } or class { ... } skips to the matching '}'.
+func (parser *Parser) synchronize(start int) {
+	depth := 0
+	for _, t := range parser.tokens[start:parser.pos] {
+		switch t.Type {
+		case lexer.LBraceToken:
+			depth++
+		case lexer.RBraceToken:
+			depth--
+		}
+	}
+	if depth < 0 {
+		depth = 0
 	}
 
-	return program
+	for t := parser.peek(); t != nil && t.Type != lexer.EOFToken; t = parser.peek() {
+		switch t.Type {
+		case lexer.SemicolonToken:
+			parser.eat()
+			if depth == 0 {
+				return
+			}
+			continue
+		case lexer.LBraceToken:
+			depth++
+		case lexer.RBraceToken:
+			if depth == 0 {
+				// always make progress, a stray '}' at top level would otherwise loop forever
+				if parser.pos == start {
+					parser.eat()
+				}
+				return
+			}
+			depth--
+			if depth == 0 {
+				parser.eat()
+				// var m = match x { ... }; ends at the ';', not the '}'
+				if next := parser.peek(); next != nil && next.Type == lexer.SemicolonToken {
+					parser.eat()
+				}
+				return
+			}
+		}
+		parser.eat()
+	}
 }
 
 func (parser *Parser) parseStatement() ast.ASTNode {
 	tok := parser.peek()
 	if tok == nil {
-		panic("unexpected end of input")
+		parser.errorAtToken(tok, "unexpected end of input")
 	}
 
 	switch tok.Type {
@@ -26,34 +124,68 @@ func (parser *Parser) parseStatement() ast.ASTNode {
 		return parser.parseVarDecl()
 	case lexer.FuncToken:
 		return parser.parseFuncDecl()
+	case lexer.StructToken:
+		return parser.parseStructDecl()
+	case lexer.ClassToken:
+		return parser.parseClassDecl()
+	case lexer.EnumToken:
+		return parser.parseEnumDecl()
 	case lexer.ReturnToken:
 		return parser.parseReturnStatement()
+	case lexer.IfToken:
+		return parser.parseIfStatement()
+	case lexer.WhileToken:
+		return parser.parseWhileStatement()
+	case lexer.ForToken:
+		return parser.parseForStatement()
+	case lexer.DeleteToken:
+		return parser.parseDeleteStatement()
+	case lexer.TryToken:
+		return parser.parseTryStatement()
+	case lexer.ThrowToken:
+		return parser.parseThrowStatement()
+	case lexer.BreakToken:
+		parser.eat() // eat 'break'
+		parser.expect(lexer.SemicolonToken, "expected ';' after 'break'")
+		return &ast.BreakNode{Pos: parser.posFrom(tok)}
+	case lexer.ContinueToken:
+		parser.eat() // eat 'continue'
+		parser.expect(lexer.SemicolonToken, "expected ';' after 'continue'")
+		return &ast.ContinueNode{Pos: parser.posFrom(tok)}
 	case lexer.LBraceToken:
 		return parser.parseBody()
-	case lexer.IdentifierToken:
-		if parser.peekAhead(1) != nil && parser.peekAhead(1).Type == lexer.EqualToken {
-			return parser.parseAssignment()
+	case lexer.IdentifierToken, lexer.LParenToken, lexer.IncrementToken, lexer.DecrementToken,
+		lexer.ThisToken, lexer.SuperToken, lexer.MatchToken:
+		expr := parser.parseAssignmentOrExpression()
+
+		switch expr.(type) {
+		case *ast.AssignmentNode, *ast.ParallelAssignmentNode:
+			parser.expect(lexer.SemicolonToken, "expected ';' after expression")
+			return expr
 		}
 
-		expr := parser.parseExpression()
 		if parser.peek() != nil && parser.peek().Type == lexer.SemicolonToken {
 			parser.eat() // eat ';'
 		}
-		
+
 		return expr
 	default:
-		panic("unknown statement starting at token: " + tok.String())
+		parser.errorAtToken(tok, "unexpected %s at start of statement", describe(tok))
+		return nil
 	}
 }
 
-func (parser *Parser) parseBody() ast.ASTNode {
-	parser.eat() // eat '{'
+func (parser *Parser) parseBody() *ast.BodyNode {
+	start := parser.expect(lexer.LBraceToken, "expected '{' at start of block")
 	block := &ast.BodyNode{Statements: []ast.ASTNode{}}
 
-	for t := parser.peek(); t != nil && t.Type != lexer.RBraceToken; t = parser.peek() {
-		block.Statements = append(block.Statements, parser.parseStatement())
+	for t := parser.peek(); t != nil && t.Type != lexer.RBraceToken && t.Type != lexer.EOFToken; t = parser.peek() {
+		if stmt := parser.parseStatementRecovering(); stmt != nil {
+			block.Statements = append(block.Statements, stmt)
+		}
 	}
 
-	parser.eat() // eat '}'
+	parser.expect(lexer.RBraceToken, "expected '}' after block")
+	block.Pos = parser.posFrom(start)
 	return block
 }
@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"pcl/src/frontend/ast"
+	"pcl/src/frontend/lexer"
+)
+
+func (parser *Parser) parseWhileStatement() ast.ASTNode {
+	start := parser.expect(lexer.WhileToken, "expected 'while'")
+	parser.expect(lexer.LParenToken, "expected '(' after 'while'")
+
+	condition := parser.parseExpression()
+
+	parser.expect(lexer.RParenToken, "expected ')' after while condition")
+
+	body := parser.parseBody()
+
+	return &ast.WhileNode{
+		Pos:       parser.posFrom(start),
+		Condition: condition,
+		Body:      body,
+	}
+}
+
+func (parser *Parser) parseForStatement() ast.ASTNode {
+	start := parser.expect(lexer.ForToken, "expected 'for'")
+
+	if parser.peek() != nil && parser.peek().Type == lexer.IdentifierToken {
+		return parser.parseForInStatement(start)
+	}
+
+	parser.expect(lexer.LParenToken, "expected '(' or loop variable after 'for'")
+
+	forNode := &ast.ForNode{}
+
+	// init: 'var i = 0;', 'i = 0;' or just ';'
+	switch parser.peek().Type {
+	case lexer.SemicolonToken:
+		parser.eat()
+	case lexer.VarToken:
+		forNode.Init = parser.parseVarDecl() // eats its own ';'
+	default:
+		forNode.Init = parser.parseAssignmentOrExpression()
+		parser.expect(lexer.SemicolonToken, "expected ';' after for initializer")
+	}
+
+	if parser.peek().Type != lexer.SemicolonToken {
+		forNode.Condition = parser.parseExpression()
+	}
+	parser.expect(lexer.SemicolonToken, "expected ';' after for condition")
+
+	if parser.peek().Type != lexer.RParenToken {
+		forNode.Update = parser.parseAssignmentOrExpression()
+	}
+	parser.expect(lexer.RParenToken, "expected ')' after for clauses")
+
+	forNode.Body = parser.parseBody()
+	forNode.Pos = parser.posFrom(start)
+	return forNode
+}
+
+func (parser *Parser) parseForInStatement(start *lexer.Token) ast.ASTNode {
+	variable := parser.expect(lexer.IdentifierToken, "expected loop variable after 'for'")
+	parser.expect(lexer.InToken, "expected 'in' after loop variable")
+
+	iterable := parser.parseExpression()
+
+	body := parser.parseBody()
+
+	return &ast.ForInNode{
+		Pos:      parser.posFrom(start),
+		Variable: variable.Value,
+		Iterable: iterable,
+		Body:     body,
+	}
+}
@@ -1,14 +1,33 @@
 package parser
 
-import "pcl/src/frontend/lexer"
+import (
+	"fmt"
+	"pcl/src/frontend/ast"
+	"pcl/src/frontend/lexer"
+	"pcl/src/frontend/source"
+)
 
 type Parser struct {
-	tokens []lexer.Token
-	pos    int
+	tokens      []lexer.Token
+	pos         int
+	diagnostics []source.Diagnostic
+
+	// indexes of tokens that came right after an ErrorToken
+	afterError map[int]bool
 }
 
+// NewParser drops the lexer's ErrorTokens, which it has already reported, so
+// the rest of the file still gets parsed and checked
 func NewParser(tokens []lexer.Token) *Parser {
-	return &Parser{tokens: tokens, pos: 0}
+	parser := &Parser{pos: 0, afterError: map[int]bool{}}
+	for _, t := range tokens {
+		if t.Type == lexer.ErrorToken {
+			parser.afterError[len(parser.tokens)] = true
+			continue
+		}
+		parser.tokens = append(parser.tokens, t)
+	}
+	return parser
 }
 
 // --- token helpers ---
@@ -27,6 +46,11 @@ func (p *Parser) peekAhead(n int) *lexer.Token {
 	return &p.tokens[pos]
 }
 
+// the most recently consumed token
+func (p *Parser) previous() *lexer.Token {
+	return &p.tokens[max(p.pos-1, 0)]
+}
+
 func (p *Parser) eat() *lexer.Token {
 	if p.pos >= len(p.tokens) {
 		return nil
@@ -37,8 +61,73 @@ func (p *Parser) eat() *lexer.Token {
 }
 
 func (p *Parser) expect(tType lexer.TokenType, msg string) *lexer.Token {
-	if t := p.peek(); t != nil && t.Type == tType {
+	t := p.peek()
+	if t != nil && t.Type == tType {
 		return p.eat()
 	}
-	panic("unexpected token: " + msg)
+	p.errorAtToken(t, "%s, found %s", msg, describe(t))
+	return nil
+}
+
+// --- error handling ---
+
+// syntaxError is what the parse functions panic with; it is recovered one
+// statement up, recorded, and parsing resumes after the broken statement
+type syntaxError struct {
+	diagnostic source.Diagnostic
+}
+
+func (p *Parser) errorAt(span source.Span, format string, args ...any) {
+	panic(syntaxError{source.Diagnostic{
+		Message: fmt.Sprintf(format, args...),
+		Span:    span,
+	}})
+}
+
+func (p *Parser) errorAtToken(t *lexer.Token, format string, args ...any) {
+	if t == nil {
+		t = &p.tokens[len(p.tokens)-1] // ran off the end, blame the EOF token
+	}
+
+	hint := keywordHint(t)
+	if hint == "" && p.pos > 0 {
+		hint = keywordHint(p.previous()) // e.g. "retrun 5;" fails on the 5
+	}
+
+	panic(syntaxError{source.Diagnostic{
+		Message: fmt.Sprintf(format, args...),
+		Span:    t.Span,
+		Hint:    hint,
+	}})
+}
+
+// keywordHint suggests a keyword when an identifier looks like a misspelt one
+func keywordHint(t *lexer.Token) string {
+	if t.Type != lexer.IdentifierToken {
+		return ""
+	}
+	if keyword, ok := source.Closest(t.Value, lexer.Keywords()); ok {
+		return fmt.Sprintf("did you mean the keyword '%s'?", keyword)
+	}
+	return ""
+}
+
+// describe renders a token for "found ..." messages
+func describe(t *lexer.Token) string {
+	if t == nil || t.Type == lexer.EOFToken {
+		return "end of input"
+	}
+	return "'" + t.Value + "'"
+}
+
+// --- position helpers ---
+
+// posFrom spans from start up to the end of the last consumed token
+func (p *Parser) posFrom(start *lexer.Token) ast.Pos {
+	return ast.Pos{Span: start.Span.To(p.previous().Span)}
+}
+
+// posBetween spans from the start of first to the end of last
+func posBetween(first, last ast.ASTNode) ast.Pos {
+	return ast.Pos{Span: first.Position().To(last.Position())}
 }
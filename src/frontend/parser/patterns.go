@@ -0,0 +1,222 @@
+package parser
+
+import (
+	"pcl/src/frontend/ast"
+	"pcl/src/frontend/lexer"
+)
+
+// match subject { pattern [if guard] => expr, ... }, a trailing comma is fine
+func (parser *Parser) parseMatch() ast.ASTNode {
+	start := parser.expect(lexer.MatchToken, "expected 'match'")
+	subject := parser.parseExpression()
+	parser.expect(lexer.LBraceToken, "expected '{' after match subject")
+
+	match := &ast.MatchNode{Subject: subject, Arms: []ast.MatchArm{}}
+
+	for parser.peek() != nil && parser.peek().Type != lexer.RBraceToken {
+		arm := ast.MatchArm{Pattern: parser.parsePattern()}
+
+		if t := parser.peek(); t != nil && t.Type == lexer.IfToken {
+			parser.eat() // eat 'if'
+			arm.Guard = parser.parseExpression()
+		}
+
+		parser.expect(lexer.FatArrowToken, "expected '=>' after match pattern")
+		arm.Body = parser.parseExpression()
+		match.Arms = append(match.Arms, arm)
+
+		if parser.peek() == nil || parser.peek().Type != lexer.CommaToken {
+			break
+		}
+		parser.eat() // eat ','
+	}
+
+	parser.expect(lexer.RBraceToken, "expected '}' after match arms")
+
+	match.Pos = parser.posFrom(start)
+	return match
+}
+
+func (parser *Parser) parsePattern() ast.ASTNode {
+	token := parser.peek()
+	if token == nil {
+		parser.errorAtToken(token, "unexpected end of input in pattern")
+	}
+
+	switch token.Type {
+	case lexer.IdentifierToken:
+		switch token.Value {
+		case "_":
+			parser.eat()
+			return &ast.WildcardPatternNode{Pos: ast.Pos{Span: token.Span}}
+		case "true", "false", "nil":
+			// these are globals, not keywords, but as patterns they mean the value
+			return parser.parseLiteralPattern()
+		}
+
+		if next := parser.peekAhead(1); next != nil && next.Type == lexer.DotToken {
+			return parser.parseVariantPattern()
+		}
+
+		parser.eat()
+		return &ast.BindingPatternNode{Pos: ast.Pos{Span: token.Span}, Name: token.Value}
+
+	case lexer.NumberToken, lexer.StringToken, lexer.MinusToken:
+		return parser.parseLiteralPattern()
+
+	case lexer.LBracketToken:
+		return parser.parseArrayPattern()
+
+	case lexer.LBraceToken:
+		return parser.parseMapPattern()
+
+	default:
+		parser.errorAtToken(token, "expected a pattern, found %s", describe(token))
+		return nil
+	}
+}
+
+// a literal, or a range when '..' or '..=' follows it
+func (parser *Parser) parseLiteralPattern() ast.ASTNode {
+	start := parser.peek()
+	low := parser.parsePatternLiteral()
+
+	t := parser.peek()
+	if t == nil || (t.Type != lexer.RangeToken && t.Type != lexer.RangeInclusiveToken) {
+		return &ast.LiteralPatternNode{Pos: ast.Pos{Span: low.Position()}, Value: low}
+	}
+
+	parser.eat() // eat '..' or '..='
+	high := parser.parsePatternLiteral()
+
+	return &ast.RangePatternNode{
+		Pos:       parser.posFrom(start),
+		Low:       low,
+		High:      high,
+		Inclusive: t.Type == lexer.RangeInclusiveToken,
+	}
+}
+
+// only plain values: numbers (possibly negated), strings, true, false and nil
+func (parser *Parser) parsePatternLiteral() ast.ASTNode {
+	token := parser.peek()
+	if token == nil {
+		parser.errorAtToken(token, "unexpected end of input in pattern")
+	}
+
+	switch {
+	case token.Type == lexer.MinusToken:
+		parser.eat()
+		if next := parser.peek(); next == nil || next.Type != lexer.NumberToken {
+			parser.errorAtToken(next, "expected a number after '-' in pattern, found %s", describe(next))
+		}
+		operand := parser.parsePrimary()
+		return &ast.UnaryOpNode{Pos: parser.posFrom(token), Operator: "-", Operand: operand}
+	case token.Type == lexer.NumberToken, token.Type == lexer.StringToken:
+		return parser.parsePrimary()
+	case token.Type == lexer.IdentifierToken && (token.Value == "true" || token.Value == "false" || token.Value == "nil"):
+		return parser.parseIdentifier()
+	}
+
+	parser.errorAtToken(token, "expected a literal in pattern, found %s", describe(token))
+	return nil
+}
+
+// [p, p, ...rest], the rest element must come last
+func (parser *Parser) parseArrayPattern() ast.ASTNode {
+	start := parser.expect(lexer.LBracketToken, "expected '['")
+	pattern := &ast.ArrayPatternNode{Elements: []ast.ASTNode{}}
+
+	for t := parser.peek(); t != nil && t.Type != lexer.RBracketToken; t = parser.peek() {
+		if t.Type == lexer.EllipsisToken {
+			parser.eat() // eat '...'
+			name := parser.expect(lexer.IdentifierToken, "expected a name after '...'")
+			if name.Value == "_" {
+				pattern.Rest = &ast.WildcardPatternNode{Pos: ast.Pos{Span: name.Span}}
+			} else {
+				pattern.Rest = &ast.BindingPatternNode{Pos: ast.Pos{Span: name.Span}, Name: name.Value}
+			}
+
+			if next := parser.peek(); next != nil && next.Type == lexer.CommaToken {
+				parser.errorAtToken(next, "the '...' element must be the last one in an array pattern")
+			}
+			break
+		}
+
+		pattern.Elements = append(pattern.Elements, parser.parsePattern())
+
+		if parser.peek() == nil || parser.peek().Type != lexer.CommaToken {
+			break
+		}
+		parser.eat() // eat ','
+	}
+
+	parser.expect(lexer.RBracketToken, "expected ']' after array pattern")
+
+	pattern.Pos = parser.posFrom(start)
+	return pattern
+}
+
+// {key, key: pattern, "quoted key": pattern}
+func (parser *Parser) parseMapPattern() ast.ASTNode {
+	start := parser.expect(lexer.LBraceToken, "expected '{'")
+	pattern := &ast.MapPatternNode{Keys: []string{}, Values: []ast.ASTNode{}}
+
+	for t := parser.peek(); t != nil && t.Type != lexer.RBraceToken; t = parser.peek() {
+		if t.Type != lexer.IdentifierToken && t.Type != lexer.StringToken {
+			parser.errorAtToken(t, "expected a key in map pattern, found %s", describe(t))
+		}
+		key := parser.eat()
+
+		var value ast.ASTNode
+		if next := parser.peek(); next != nil && next.Type == lexer.ColonToken {
+			parser.eat() // eat ':'
+			value = parser.parsePattern()
+		} else if key.Type == lexer.IdentifierToken {
+			value = &ast.BindingPatternNode{Pos: ast.Pos{Span: key.Span}, Name: key.Value}
+		} else {
+			parser.errorAtToken(key, "a quoted key needs a pattern, as in %s: name", key.Value)
+		}
+
+		pattern.Keys = append(pattern.Keys, key.Value)
+		pattern.Values = append(pattern.Values, value)
+
+		if parser.peek() == nil || parser.peek().Type != lexer.CommaToken {
+			break
+		}
+		parser.eat() // eat ','
+	}
+
+	parser.expect(lexer.RBraceToken, "expected '}' after map pattern")
+
+	pattern.Pos = parser.posFrom(start)
+	return pattern
+}
+
+// Enum.Variant or Enum.Variant(p, ...)
+func (parser *Parser) parseVariantPattern() ast.ASTNode {
+	enum := parser.eat()
+	parser.expect(lexer.DotToken, "expected '.' after enum name")
+	variant := parser.expect(lexer.IdentifierToken, "expected variant name after '.'")
+
+	pattern := &ast.VariantPatternNode{Enum: enum.Value, Variant: variant.Value}
+
+	if t := parser.peek(); t != nil && t.Type == lexer.LParenToken {
+		parser.eat() // eat '('
+		pattern.Fields = []ast.ASTNode{}
+
+		for t := parser.peek(); t != nil && t.Type != lexer.RParenToken; t = parser.peek() {
+			pattern.Fields = append(pattern.Fields, parser.parsePattern())
+
+			if parser.peek() == nil || parser.peek().Type != lexer.CommaToken {
+				break
+			}
+			parser.eat() // eat ','
+		}
+
+		parser.expect(lexer.RParenToken, "expected ')' after variant pattern fields")
+	}
+
+	pattern.Pos = parser.posFrom(enum)
+	return pattern
+}
@@ -6,14 +6,14 @@ import (
 )
 
 func (p *Parser) parseReturnStatement() ast.ASTNode {
-    p.expect(lexer.ReturnToken, "expected 'return'")
+	start := p.expect(lexer.ReturnToken, "expected 'return'")
 
-    var value ast.ASTNode = nil
-    if p.peek().Type != lexer.SemicolonToken {
-        value = p.parseExpression()
-    }
+	var value ast.ASTNode = nil
+	if p.peek().Type != lexer.SemicolonToken {
+		value = p.parseExpression()
+	}
 
-    p.expect(lexer.SemicolonToken, "expected ';' after return")
+	p.expect(lexer.SemicolonToken, "expected ';' after return")
 
-    return &ast.ReturnNode{Value: value}
+	return &ast.ReturnNode{Pos: p.posFrom(start), Value: value}
 }
@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"pcl/src/frontend/ast"
+	"pcl/src/frontend/lexer"
+)
+
+// struct Point { x, y }, a trailing comma is fine
+func (parser *Parser) parseStructDecl() ast.ASTNode {
+	start := parser.expect(lexer.StructToken, "expected 'struct'")
+	name := parser.expect(lexer.IdentifierToken, "expected struct name after 'struct'")
+	parser.expect(lexer.LBraceToken, "expected '{' after struct name")
+
+	fields := parser.parseFieldList(lexer.RBraceToken, "struct "+name.Value)
+	parser.expect(lexer.RBraceToken, "expected '}' after struct fields")
+
+	return &ast.StructDeclNode{Pos: parser.posFrom(start), Name: name.Value, Fields: fields}
+}
+
+// class Name [extends Base] { name(params) { body } ... }
+func (parser *Parser) parseClassDecl() ast.ASTNode {
+	start := parser.expect(lexer.ClassToken, "expected 'class'")
+	name := parser.expect(lexer.IdentifierToken, "expected class name after 'class'")
+
+	class := &ast.ClassDeclNode{Name: name.Value, Methods: []*ast.FunctionLiteralNode{}}
+
+	if t := parser.peek(); t != nil && t.Type == lexer.ExtendsToken {
+		parser.eat() // eat 'extends'
+		base := parser.expect(lexer.IdentifierToken, "expected superclass name after 'extends'")
+		class.Superclass = &ast.IdentifierNode{Pos: ast.Pos{Span: base.Span}, Name: base.Value}
+	}
+
+	parser.expect(lexer.LBraceToken, "expected '{' after class name")
+
+	seen := map[string]bool{}
+	for parser.peek() != nil && parser.peek().Type != lexer.RBraceToken {
+		methodName := parser.expect(lexer.IdentifierToken, "expected method name in class body")
+		if seen[methodName.Value] {
+			parser.errorAtToken(methodName, "duplicate method '%s' in class %s", methodName.Value, name.Value)
+		}
+		seen[methodName.Value] = true
+
+		method := parser.parseFunctionSignature(methodName)
+		method.Name = methodName.Value
+		class.Methods = append(class.Methods, method)
+	}
+
+	parser.expect(lexer.RBraceToken, "expected '}' after class body")
+
+	class.Pos = parser.posFrom(start)
+	return class
+}
+
+// enum Name { Variant, Variant(field, ...), ... }, a trailing comma is fine
+func (parser *Parser) parseEnumDecl() ast.ASTNode {
+	start := parser.expect(lexer.EnumToken, "expected 'enum'")
+	name := parser.expect(lexer.IdentifierToken, "expected enum name after 'enum'")
+	parser.expect(lexer.LBraceToken, "expected '{' after enum name")
+
+	enum := &ast.EnumDeclNode{Name: name.Value, Variants: []ast.EnumVariant{}}
+	seen := map[string]bool{}
+
+	for parser.peek() != nil && parser.peek().Type != lexer.RBraceToken {
+		variantName := parser.expect(lexer.IdentifierToken, "expected variant name")
+		if seen[variantName.Value] {
+			parser.errorAtToken(variantName, "duplicate variant '%s' in enum %s", variantName.Value, name.Value)
+		}
+		seen[variantName.Value] = true
+
+		variant := ast.EnumVariant{Name: variantName.Value}
+		if t := parser.peek(); t != nil && t.Type == lexer.LParenToken {
+			parser.eat() // eat '('
+			variant.Fields = parser.parseFieldList(lexer.RParenToken, "variant "+variantName.Value)
+			parser.expect(lexer.RParenToken, "expected ')' after variant fields")
+		}
+		enum.Variants = append(enum.Variants, variant)
+
+		if parser.peek() == nil || parser.peek().Type != lexer.CommaToken {
+			break
+		}
+		parser.eat() // eat ','
+	}
+
+	parser.expect(lexer.RBraceToken, "expected '}' after enum variants")
+
+	enum.Pos = parser.posFrom(start)
+	return enum
+}
+
+// comma separated field names up to (not including) the closing token, owner
+// names the struct or variant for the duplicate field error
+func (parser *Parser) parseFieldList(closing lexer.TokenType, owner string) []string {
+	fields := []string{}
+	seen := map[string]bool{}
+
+	for parser.peek() != nil && parser.peek().Type != closing {
+		field := parser.expect(lexer.IdentifierToken, "expected field name")
+		if seen[field.Value] {
+			parser.errorAtToken(field, "duplicate field '%s' in %s", field.Value, owner)
+		}
+		seen[field.Value] = true
+		fields = append(fields, field.Value)
+
+		if parser.peek() == nil || parser.peek().Type != lexer.CommaToken {
+			break
+		}
+		parser.eat() // eat ','
+	}
+
+	return fields
+}
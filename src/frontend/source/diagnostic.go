@@ -0,0 +1,18 @@
+package source
+
+import "fmt"
+
+// Diagnostic is a problem found in the source, reported instead of aborting
+// so a single run can surface every error in a file
+type Diagnostic struct {
+	Message string
+	Span    Span
+	Label   string // short note printed next to the underline, optional
+	Hint    string // suggestion printed after the snippet, optional
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s", d.Span, d.Message)
+}
+
+func (d Diagnostic) Error() string { return d.String() }
@@ -0,0 +1,111 @@
+package source
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ansi escapes used when Color is on
+const (
+	colorReset = "\x1b[0m"
+	colorBold  = "\x1b[1m"
+	colorRed   = "\x1b[1;31m"
+	colorBlue  = "\x1b[1;34m"
+	colorCyan  = "\x1b[1;36m"
+)
+
+// Renderer formats diagnostics against the source they came from:
+//
+//	syntax error: unexpected character '@'
+//	  --> main.pcl:3:11
+//	   |
+//	 3 | var c = 1 @ 2;
+//	   |           ^ label
+//	   = help: hint
+type Renderer struct {
+	FileName string
+	Source   string
+	Offset   int // byte offset Source starts at, see lexer.Lexer.Offset
+	Color    bool
+}
+
+func (r *Renderer) paint(color, text string) string {
+	if !r.Color {
+		return text
+	}
+	return color + text + colorReset
+}
+
+// Render returns the diagnostic as a block of lines ending in a newline; kind
+// is the headline, e.g. "syntax error"
+func (r *Renderer) Render(kind string, d Diagnostic) string {
+	sb := &strings.Builder{}
+
+	gutter := strings.Repeat(" ", len(strconv.Itoa(d.Span.Line)))
+
+	sb.WriteString(r.paint(colorRed, kind+":") + " " + r.paint(colorBold, d.Message) + "\n")
+	sb.WriteString(fmt.Sprintf("%s%s %s:%s\n", gutter, r.paint(colorBlue, "-->"), r.FileName, d.Span))
+
+	// the span can point outside this source, e.g. a repl line calling a
+	// function typed in earlier; then the location above is all we can show
+	if line, ok := r.snippet(d.Span); ok {
+		bar := r.paint(colorBlue, "|")
+		sb.WriteString(fmt.Sprintf("%s %s\n", gutter, bar))
+		sb.WriteString(fmt.Sprintf("%s %s %s\n", r.paint(colorBlue, strconv.Itoa(d.Span.Line)), bar, line))
+
+		underline := r.paint(colorRed, r.underline(line, d.Span))
+		if d.Label != "" {
+			underline += " " + r.paint(colorRed, d.Label)
+		}
+		sb.WriteString(fmt.Sprintf("%s %s %s\n", gutter, bar, underline))
+	}
+
+	if d.Hint != "" {
+		sb.WriteString(fmt.Sprintf("%s %s %s\n", gutter, r.paint(colorBlue, "="), r.paint(colorCyan, "help:")+" "+d.Hint))
+	}
+
+	return sb.String()
+}
+
+// snippet returns the source line the span starts on, if the span belongs to
+// this source at all
+func (r *Renderer) snippet(span Span) (string, bool) {
+	if span.Start < r.Offset || span.End > r.Offset+len(r.Source) {
+		return "", false
+	}
+
+	lines := strings.Split(r.Source, "\n")
+	if span.Line < 1 || span.Line > len(lines) {
+		return "", false
+	}
+	return strings.TrimRight(lines[span.Line-1], "\r"), true
+}
+
+// underline puts carets under the part of line the span covers; spans running
+// over several lines are cut off at the end of the first one
+func (r *Renderer) underline(line string, span Span) string {
+	// columns count characters, find the byte offset they start at
+	column := 0
+	for i := 1; i < span.Column && column < len(line); i++ {
+		_, size := utf8.DecodeRuneInString(line[column:])
+		column += size
+	}
+
+	// pad with the line's own tabs so the carets stay aligned
+	sb := &strings.Builder{}
+	for _, char := range line[:column] {
+		if char == '\t' {
+			sb.WriteByte('\t')
+		} else {
+			sb.WriteByte(' ')
+		}
+	}
+
+	end := min(column+max(span.End-span.Start, 0), len(line))
+	width := max(utf8.RuneCountInString(line[column:end]), 1)
+
+	sb.WriteString(strings.Repeat("^", width))
+	return sb.String()
+}
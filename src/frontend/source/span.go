@@ -0,0 +1,27 @@
+package source
+
+import "fmt"
+
+// Span locates a piece of source: byte offsets [Start, End) plus the
+// 1-based line and column where it begins
+type Span struct {
+	Start, End   int
+	Line, Column int
+}
+
+func (span Span) String() string {
+	return fmt.Sprintf("%d:%d", span.Line, span.Column)
+}
+
+// Shift moves the byte offsets by delta, line and column stay as they are
+func (span Span) Shift(delta int) Span {
+	span.Start += delta
+	span.End += delta
+	return span
+}
+
+// To returns a span covering everything from span up to the end of other
+func (span Span) To(other Span) Span {
+	span.End = max(span.End, other.End)
+	return span
+}
@@ -0,0 +1,59 @@
+package source
+
+import "sort"
+
+// Closest returns the candidate nearest to name by edit distance, as long as it
+// is close enough to plausibly be a typo of it. Ties go to the alphabetically
+// first candidate so suggestions are stable between runs.
+func Closest(name string, candidates []string) (string, bool) {
+	sorted := append([]string{}, candidates...)
+	sort.Strings(sorted)
+
+	// roughly one edit per three characters, and never a complete rewrite
+	limit := max(1, len([]rune(name))/3)
+
+	best, bestDistance := "", limit+1
+	for _, candidate := range sorted {
+		if candidate == name {
+			continue
+		}
+		distance := editDistance(name, candidate)
+		if distance < bestDistance && distance < len([]rune(name)) {
+			best, bestDistance = candidate, distance
+		}
+	}
+
+	return best, best != ""
+}
+
+// editDistance counts insertions, deletions, substitutions and swaps of two
+// neighbouring characters (the optimal string alignment distance), so "fucn"
+// is one edit away from "func"
+func editDistance(a, b string) int {
+	x, y := []rune(a), []rune(b)
+
+	d := make([][]int, len(x)+1)
+	for i := range d {
+		d[i] = make([]int, len(y)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(x); i++ {
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+
+			if i > 1 && j > 1 && x[i-1] == y[j-2] && x[i-2] == y[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(x)][len(y)]
+}
@@ -1,35 +1,122 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"pcl/src/frontend/lexer"
 	"pcl/src/frontend/parser"
+	"pcl/src/frontend/source"
+	"pcl/src/runtime"
 	"pcl/src/runtime/interpreter"
+	"sort"
+	"strings"
 )
 
-func processSource(source string) {
-	lexer := lexer.NewLexer(source)
-	tokens := lexer.Tokenize()
+// processSource runs one chunk of source and reports whether it succeeded; in the
+// repl the value of the last statement is echoed back, scripts only produce
+// output through print
+func processSource(interp *interpreter.Interpreter, fileName string, sourceCode string, offset int, echo bool) bool {
+	renderer := &source.Renderer{FileName: fileName, Source: sourceCode, Offset: offset, Color: colorOutput()}
 
-    /*
-	for _, token := range tokens {
-		fmt.Println(token.String())
-	}
+	lexer := lexer.NewLexer(sourceCode)
+	lexer.Offset = offset
+	tokens, diagnostics := lexer.Tokenize()
+
+	/*
+		for _, token := range tokens {
+			fmt.Println(token.String())
+		}
 
-	fmt.Println()
-    */
+		fmt.Println()
+	*/
 
+	// the parser skips the error tokens, so one run reports every error
 	parser := parser.NewParser(tokens)
-	ast := parser.GenerateAST()
+	ast, parseDiagnostics := parser.GenerateAST()
+	diagnostics = append(diagnostics, parseDiagnostics...)
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		return diagnostics[i].Span.Start < diagnostics[j].Span.Start
+	})
+
+	if len(diagnostics) > 0 {
+		printSyntaxErrors(renderer, diagnostics)
+		return false
+	}
+
+	result, err := interp.Evaluate(ast)
+	if err != nil {
+		printRuntimeError(renderer, err)
+		return false
+	}
+
+	if _, isNil := result.(*runtime.NilValue); echo && !isNil {
+		fmt.Println(runtime.Display(result))
+	}
+
+	return true
+}
+
+// colorOutput reports whether errors go to a terminal, and so may use color
+func colorOutput() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := os.Stderr.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func printSyntaxErrors(renderer *source.Renderer, diagnostics []source.Diagnostic) {
+	for _, diagnostic := range diagnostics {
+		fmt.Fprint(os.Stderr, renderer.Render("syntax error", diagnostic))
+	}
+}
 
-	fmt.Println(ast)
+// printRuntimeError prints the error followed by the pcl call stack, innermost
+// call first: each frame shows where that function was when the error happened
+func printRuntimeError(renderer *source.Renderer, err error) {
+	fileName := renderer.FileName
+
+	runtimeErr, ok := err.(*runtime.RuntimeError)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "%s: runtime error: %v\n", fileName, err)
+		return
+	}
 
-    interpreter := interpreter.NewInterpreter()
-    result := interpreter.Evaluate(ast)
+	fmt.Fprint(os.Stderr, renderer.Render("runtime error", source.Diagnostic{
+		Message: runtimeErr.Message,
+		Span:    runtimeErr.Span,
+		Label:   runtimeErr.Label,
+		Hint:    runtimeErr.Hint,
+	}))
+	fmt.Fprintln(os.Stderr, "stack trace (most recent call first):")
 
-    fmt.Println(result)
+	// runaway recursion would print thousands of identical lines, fold them
+	previous, repeated := "", 0
+	flush := func() {
+		if repeated > 0 {
+			fmt.Fprintf(os.Stderr, "  ... previous line repeated %d more times\n", repeated)
+		}
+		repeated = 0
+	}
+	emit := func(line string) {
+		if line == previous {
+			repeated++
+			return
+		}
+		flush()
+		fmt.Fprintln(os.Stderr, line)
+		previous = line
+	}
+
+	position := runtimeErr.Span
+	for i := len(runtimeErr.Stack) - 1; i >= 0; i-- {
+		frame := runtimeErr.Stack[i]
+		emit(fmt.Sprintf("  at %s (%s:%s)", frame.Function, fileName, position))
+		position = frame.CallSite
+	}
+	emit(fmt.Sprintf("  at <script> (%s:%s)", fileName, position))
+	flush()
 }
 
 func main() {
@@ -45,26 +132,41 @@ func main() {
 			fmt.Printf("error reading file: %v\n", err)
 			return
 		}
-		processSource(string(sourceCode))
+		if !processSource(interpreter.NewInterpreter(), sourceFile, string(sourceCode), 0, false) {
+			os.Exit(1)
+		}
 	} else {
 		fmt.Println("entering repl mode. type 'exit' to quit.")
-		scanner := bufio.NewScanner(os.Stdin)
+
+		// one interpreter for the whole session so variables survive between lines
+		interp := interpreter.NewInterpreter()
+		offset := 0
+
+		// lines come through the interpreter's reader, so input() in a line
+		// reads the line after it rather than racing the repl for stdin
+		stdin := interp.Stdin()
+		var err error
 
 		for {
 			fmt.Print(">> ")
-			if !scanner.Scan() {
+			var line string
+			line, err = stdin.ReadString('\n')
+			if err != nil && line == "" {
 				break // eof or error
 			}
 
-			input := scanner.Text()
+			input := strings.TrimRight(line, "\r\n")
 			if input == "exit" {
 				break
 			}
 
-			processSource(input)
+			// every input gets its own byte range, so an error inside a function
+			// typed in earlier is not drawn against this line
+			processSource(interp, "<repl>", input, offset, true)
+			offset += len(input) + 1
 		}
 
-		if err := scanner.Err(); err != nil {
+		if err != nil && err != io.EOF {
 			fmt.Printf("input error: %v\n", err)
 		}
 	}
@@ -0,0 +1,80 @@
+package runtime
+
+import "fmt"
+
+// ClassValue is what a class declaration evaluates to. Calling it makes an
+// instance and runs init, looked up like any other method.
+type ClassValue struct {
+	Name       string
+	Superclass *ClassValue // nil for a root class
+	Methods    map[string]*FunctionValue
+}
+
+func (c *ClassValue) Type() ValueType { return ClassValueType }
+func (c *ClassValue) String() string {
+	return fmt.Sprintf("ClassValue { Name: %s }", c.Name)
+}
+
+// FindMethod looks in the class first, then up the extends chain
+func (c *ClassValue) FindMethod(name string) (*FunctionValue, bool) {
+	for class := c; class != nil; class = class.Superclass {
+		if method, ok := class.Methods[name]; ok {
+			return method, true
+		}
+	}
+	return nil, false
+}
+
+type InstanceValue struct {
+	Class  *ClassValue
+	Fields *MapValue // string keys, in the order they were first set
+
+	// each method is bound once, so obj.m == obj.m
+	bound map[*FunctionValue]*FunctionValue
+}
+
+func NewInstance(class *ClassValue) *InstanceValue {
+	return &InstanceValue{Class: class, Fields: NewMapValue()}
+}
+
+func (i *InstanceValue) Type() ValueType  { return InstanceValueType }
+func (i *InstanceValue) TypeName() string { return i.Class.Name }
+func (i *InstanceValue) String() string {
+	return fmt.Sprintf("InstanceValue { Class: %s, Fields: %s }", i.Class.Name, i.Fields)
+}
+
+// fields shadow methods, methods come back bound to this instance
+func (i *InstanceValue) GetProperty(name string) (RuntimeValue, bool) {
+	if value, ok := i.Fields.Get(&StringValue{Value: name}); ok {
+		return value, true
+	}
+	if method, ok := i.Class.FindMethod(name); ok {
+		return i.BoundMethod(method), true
+	}
+	return nil, false
+}
+
+// BoundMethod returns method bound to this instance, the same value every time
+func (i *InstanceValue) BoundMethod(method *FunctionValue) *FunctionValue {
+	if bound, ok := i.bound[method]; ok {
+		return bound
+	}
+	if i.bound == nil {
+		i.bound = map[*FunctionValue]*FunctionValue{}
+	}
+	i.bound[method] = method.Bind(i)
+	return i.bound[method]
+}
+
+// unlike structs, instances take new fields on assignment
+func (i *InstanceValue) SetProperty(name string, value RuntimeValue) bool {
+	i.Fields.Set(&StringValue{Value: name}, value)
+	return true
+}
+
+// Bind returns a copy of the method whose scope has this set to instance
+func (f *FunctionValue) Bind(instance *InstanceValue) *FunctionValue {
+	scope := NewScope(f.Scope)
+	scope.SetVariable("this", instance)
+	return &FunctionValue{Name: f.Name, Arguments: f.Arguments, Body: f.Body, Scope: scope}
+}
@@ -0,0 +1,112 @@
+package runtime
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Display renders a value the way print / str show it to users, as opposed
+// to String() which is the debug form used when dumping values
+func Display(value RuntimeValue) string {
+	if str, ok := value.(*StringValue); ok {
+		return str.Value
+	}
+	return displayNested(value, nil)
+}
+
+// Inspect is Display with strings quoted, for messages where "1" and 1 must differ
+func Inspect(value RuntimeValue) string {
+	return displayNested(value, nil)
+}
+
+// the containers currently being printed, one reached again from inside
+// itself prints as [...] or {...} instead of recursing forever
+type printing map[RuntimeValue]bool
+
+// inside collections strings are quoted so ["a, b"] and ["a", "b"] differ
+func displayNested(value RuntimeValue, visited printing) string {
+	switch value.(type) {
+	case *ArrayValue, *MapValue, *InstanceValue, *StructInstanceValue:
+		if visited[value] {
+			if _, isArray := value.(*ArrayValue); isArray {
+				return "[...]"
+			}
+			return "{...}"
+		}
+		if visited == nil {
+			visited = printing{}
+		}
+		visited[value] = true
+		defer delete(visited, value)
+	}
+
+	switch v := value.(type) {
+	case *IntValue:
+		return strconv.Itoa(v.Value)
+	case *BigIntValue:
+		return v.Value.String()
+	case *FloatValue:
+		// keep a trailing .0 so 1.0 doesn't print like the int 1
+		str := strconv.FormatFloat(v.Value, 'g', -1, 64)
+		if !strings.ContainsAny(str, ".eIN") {
+			str += ".0"
+		}
+		return str
+	case *StringValue:
+		return strconv.Quote(v.Value)
+	case *BooleanValue:
+		return strconv.FormatBool(v.Value)
+	case *NilValue:
+		return "nil"
+	case *FunctionValue:
+		return "<function>"
+	case *NativeFunctionValue:
+		return "<native function " + v.Name + ">"
+	case *ErrorValue:
+		return "error: " + v.Message
+	case *StructValue:
+		return "<struct " + v.Name + ">"
+	case *EnumValue:
+		return "<enum " + v.Name + ">"
+	case *EnumVariantValue:
+		return "<variant " + v.FullName() + ">"
+	case *EnumInstanceValue:
+		if v.Variant.Fields == nil {
+			return v.Variant.FullName()
+		}
+		parts := make([]string, len(v.Payload))
+		for i, value := range v.Payload {
+			parts[i] = displayNested(value, visited)
+		}
+		return v.Variant.FullName() + "(" + strings.Join(parts, ", ") + ")"
+	case *ClassValue:
+		return "<class " + v.Name + ">"
+	case *InstanceValue:
+		keys, values := v.Fields.Keys(), v.Fields.Values()
+		parts := make([]string, len(keys))
+		for i := range keys {
+			parts[i] = Display(keys[i]) + ": " + displayNested(values[i], visited)
+		}
+		return v.Class.Name + "{" + strings.Join(parts, ", ") + "}"
+	case *StructInstanceValue:
+		parts := make([]string, len(v.Values))
+		for i, value := range v.Values {
+			parts[i] = v.Struct.Fields[i] + ": " + displayNested(value, visited)
+		}
+		return v.Struct.Name + "{" + strings.Join(parts, ", ") + "}"
+	case *ArrayValue:
+		parts := make([]string, len(v.Elements))
+		for i, element := range v.Elements {
+			parts[i] = displayNested(element, visited)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case *MapValue:
+		parts := make([]string, len(v.keys))
+		for i, key := range v.keys {
+			parts[i] = displayNested(key, visited) + ": " + displayNested(v.values[i], visited)
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	default:
+		return value.String()
+	}
+}
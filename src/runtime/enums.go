@@ -0,0 +1,92 @@
+package runtime
+
+import "fmt"
+
+// EnumValue is what `enum Shape { Circle(r), Empty }` declares. Its variants
+// are reached as properties: Shape.Circle is a constructor, while a variant
+// without fields like Shape.Empty is already a value.
+type EnumValue struct {
+	Name     string
+	Variants []*EnumVariantValue
+}
+
+func NewEnum(name string) *EnumValue {
+	return &EnumValue{Name: name}
+}
+
+// AddVariant appends a variant, fields is nil for one without a payload
+func (e *EnumValue) AddVariant(name string, fields []string) {
+	e.Variants = append(e.Variants, &EnumVariantValue{Enum: e, Name: name, Fields: fields})
+}
+
+func (e *EnumValue) Type() ValueType { return EnumValueType }
+func (e *EnumValue) String() string {
+	names := make([]string, len(e.Variants))
+	for i, variant := range e.Variants {
+		names[i] = variant.Name
+	}
+	return fmt.Sprintf("EnumValue { Name: %s, Variants: %v }", e.Name, names)
+}
+
+func (e *EnumValue) Variant(name string) (*EnumVariantValue, bool) {
+	for _, variant := range e.Variants {
+		if variant.Name == name {
+			return variant, true
+		}
+	}
+	return nil, false
+}
+
+func (e *EnumValue) GetProperty(name string) (RuntimeValue, bool) {
+	variant, ok := e.Variant(name)
+	if !ok {
+		return nil, false
+	}
+	if variant.Fields == nil {
+		return variant.New(nil), true
+	}
+	return variant, true
+}
+
+// one case of an enum, called with its payload to build a value
+type EnumVariantValue struct {
+	Enum   *EnumValue
+	Name   string
+	Fields []string
+}
+
+func (v *EnumVariantValue) Type() ValueType { return EnumVariantValueType }
+func (v *EnumVariantValue) String() string {
+	return fmt.Sprintf("EnumVariantValue { Enum: %s, Name: %s, Fields: %v }", v.Enum.Name, v.Name, v.Fields)
+}
+
+// the qualified name used in messages and output, Shape.Circle
+func (v *EnumVariantValue) FullName() string {
+	return v.Enum.Name + "." + v.Name
+}
+
+func (v *EnumVariantValue) New(payload []RuntimeValue) *EnumInstanceValue {
+	return &EnumInstanceValue{Variant: v, Payload: payload}
+}
+
+// a value of an enum: its tag is the variant, the payload lines up with
+// Variant.Fields. Enum values are immutable.
+type EnumInstanceValue struct {
+	Variant *EnumVariantValue
+	Payload []RuntimeValue
+}
+
+func (e *EnumInstanceValue) Type() ValueType  { return EnumInstanceValueType }
+func (e *EnumInstanceValue) TypeName() string { return e.Variant.Enum.Name }
+func (e *EnumInstanceValue) String() string {
+	return fmt.Sprintf("EnumInstanceValue { Variant: %s, Payload: %v }", e.Variant.FullName(), e.Payload)
+}
+
+func (e *EnumInstanceValue) GetProperty(name string) (RuntimeValue, bool) {
+	for i, field := range e.Variant.Fields {
+		if field == name {
+			return e.Payload[i], true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,70 @@
+package runtime
+
+import (
+	"fmt"
+	"pcl/src/frontend/source"
+)
+
+// StackFrame is one active PCL function call
+type StackFrame struct {
+	Function string      // name of the called function
+	CallSite source.Span // where it was called from
+}
+
+// RuntimeError is a failure while evaluating PCL code. Code deep in the runtime
+// raises it with just a message (panic(Errorf(...))); the interpreter fills in
+// Span and Stack from wherever it was evaluating when the error surfaced.
+type RuntimeError struct {
+	Message string
+	Span    source.Span
+	Stack   []StackFrame // outermost call first
+	Value   RuntimeValue // what a script threw; nil for errors raised by the runtime
+
+	Label string // short note for the underline when rendered, optional
+	Hint  string // e.g. a "did you mean" suggestion, optional
+}
+
+func Errorf(format string, args ...any) *RuntimeError {
+	return &RuntimeError{Message: fmt.Sprintf(format, args...)}
+}
+
+func (e *RuntimeError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Span, e.Message)
+}
+
+// Located reports whether the interpreter has already attached a position
+func (e *RuntimeError) Located() bool {
+	return e.Span.Line > 0
+}
+
+// ErrorValue is what catch receives for errors raised by the runtime, and what
+// the error() builtin creates for scripts that want to throw their own
+type ErrorValue struct {
+	Message string
+	Span    source.Span // zero until thrown
+}
+
+func (e *ErrorValue) Type() ValueType { return ErrorValueType }
+func (e *ErrorValue) String() string {
+	return fmt.Sprintf("ErrorValue { Message: %q, Pos: %s }", e.Message, e.Span)
+}
+
+func (e *ErrorValue) GetProperty(name string) (RuntimeValue, bool) {
+	switch name {
+	case "message":
+		return &StringValue{Value: e.Message}, true
+	case "line":
+		return &IntValue{Value: e.Span.Line}, true
+	case "column":
+		return &IntValue{Value: e.Span.Column}, true
+	}
+	return nil, false
+}
+
+// Caught converts an error into the value a catch block binds
+func (e *RuntimeError) Caught() RuntimeValue {
+	if e.Value != nil {
+		return e.Value
+	}
+	return &ErrorValue{Message: e.Message, Span: e.Span}
+}
@@ -2,77 +2,228 @@ package interpreter
 
 import (
 	"math"
+	"math/big"
 	"pcl/src/runtime"
 )
 
+// the numeric tower: int op int stays an exact int (promoted to a big int when
+// it overflows), anything involving a float is done in floats
 func (interpreter *Interpreter) evalArithmetic(left, right runtime.RuntimeValue, op string) runtime.RuntimeValue {
-	lInt, lIsInt := left.(*runtime.IntValue)
-	rInt, rIsInt := right.(*runtime.IntValue)
+	// string concat, checked before the operands get forced to numbers
+	if op == "+" {
+		if ls, lok := left.(*runtime.StringValue); lok {
+			if rs, rok := right.(*runtime.StringValue); rok {
+				return &runtime.StringValue{Value: ls.Value + rs.Value}
+			}
+		}
+	}
+
+	if !isNumber(left) || !isNumber(right) {
+		panic(runtime.Errorf("unsupported operand types for %s: %s and %s", op, runtime.TypeName(left), runtime.TypeName(right)))
+	}
 
-	// int modulo
-	if op == "%" && lIsInt && rIsInt {
-		if rInt.Value == 0 {
-			panic("modulo by zero")
+	if isInteger(left) && isInteger(right) {
+		return intArithmetic(left, right, op)
+	}
+
+	return floatArithmetic(asFloat(left), asFloat(right), op)
+}
+
+func intArithmetic(left, right runtime.RuntimeValue, op string) runtime.RuntimeValue {
+	l, lSmall := left.(*runtime.IntValue)
+	r, rSmall := right.(*runtime.IntValue)
+	if lSmall && rSmall {
+		if result, ok := smallIntArithmetic(l.Value, r.Value, op); ok {
+			return result
 		}
-		return &runtime.IntValue{Value: lInt.Value % rInt.Value}
 	}
 
-	if op == "/" {
-		if rIsInt && rInt.Value == 0 {
-			panic("division by zero")
+	a, _ := runtime.BigInt(left)
+	b, _ := runtime.BigInt(right)
+
+	switch op {
+	case "+":
+		return runtime.NewInt(new(big.Int).Add(a, b))
+	case "-":
+		return runtime.NewInt(new(big.Int).Sub(a, b))
+	case "*":
+		return runtime.NewInt(new(big.Int).Mul(a, b))
+	case "/":
+		if b.Sign() == 0 {
+			panic(runtime.Errorf("division by zero"))
+		}
+		// exact quotients stay ints, 7 / 2 is 3.5
+		quotient, remainder := new(big.Int).QuoRem(a, b, new(big.Int))
+		if remainder.Sign() == 0 {
+			return runtime.NewInt(quotient)
+		}
+		f, _ := new(big.Float).Quo(new(big.Float).SetInt(a), new(big.Float).SetInt(b)).Float64()
+		return &runtime.FloatValue{Value: f}
+	case "//":
+		if b.Sign() == 0 {
+			panic(runtime.Errorf("division by zero"))
+		}
+		// QuoRem truncates, floor division rounds toward negative infinity
+		quotient, remainder := new(big.Int).QuoRem(a, b, new(big.Int))
+		if remainder.Sign() != 0 && remainder.Sign() != b.Sign() {
+			quotient.Sub(quotient, big.NewInt(1))
+		}
+		return runtime.NewInt(quotient)
+	case "%":
+		if b.Sign() == 0 {
+			panic(runtime.Errorf("modulo by zero"))
 		}
-		if rf, ok := right.(*runtime.FloatValue); ok && rf.Value == 0 {
-			panic("division by zero")
+		return runtime.NewInt(new(big.Int).Rem(a, b))
+	case "**":
+		if b.Sign() < 0 {
+			f, _ := new(big.Float).SetInt(a).Float64()
+			g, _ := new(big.Float).SetInt(b).Float64()
+			return &runtime.FloatValue{Value: math.Pow(f, g)}
 		}
+		if b.BitLen() > 32 {
+			panic(runtime.Errorf("exponent %s is too large", b))
+		}
+		return runtime.NewInt(new(big.Int).Exp(a, b, nil))
+	default:
+		panic(runtime.Errorf("unsupported arithmetic op: %s", op))
 	}
+}
 
-	lf := interpreter.asFloat(left)
-	rf := interpreter.asFloat(right)
-	var res float64
-
+// smallIntArithmetic is the fast path for two machine ints; it gives up
+// (ok == false) on overflow, division by zero and anything it doesn't cover
+func smallIntArithmetic(a, b int, op string) (result runtime.RuntimeValue, ok bool) {
 	switch op {
 	case "+":
-		if ls, lok := left.(*runtime.StringValue); lok {
-			if rs, rok := right.(*runtime.StringValue); rok {
-				return &runtime.StringValue{Value: ls.Value + rs.Value}
+		sum := a + b
+		if (a > 0 && b > 0 && sum < 0) || (a < 0 && b < 0 && sum >= 0) {
+			return nil, false
+		}
+		return &runtime.IntValue{Value: sum}, true
+	case "-":
+		difference := a - b
+		if (a >= 0 && b < 0 && difference < 0) || (a < 0 && b > 0 && difference >= 0) {
+			return nil, false
+		}
+		return &runtime.IntValue{Value: difference}, true
+	case "*":
+		if a == 0 || b == 0 {
+			return &runtime.IntValue{Value: 0}, true
+		}
+		product := a * b
+		if product/b != a || (a == -1 && b == math.MinInt) || (b == -1 && a == math.MinInt) {
+			return nil, false
+		}
+		return &runtime.IntValue{Value: product}, true
+	case "/", "//", "%":
+		if b == 0 || (b == -1 && a == math.MinInt) {
+			return nil, false
+		}
+		switch {
+		case op == "%":
+			return &runtime.IntValue{Value: a % b}, true
+		case op == "//":
+			quotient := a / b
+			if a%b != 0 && (a < 0) != (b < 0) {
+				quotient--
 			}
+			return &runtime.IntValue{Value: quotient}, true
+		case a%b == 0:
+			return &runtime.IntValue{Value: a / b}, true
+		default:
+			return &runtime.FloatValue{Value: float64(a) / float64(b)}, true
 		}
-		res = lf + rf
+	}
+	return nil, false
+}
+
+func floatArithmetic(l, r float64, op string) runtime.RuntimeValue {
+	if r == 0 {
+		switch op {
+		case "/", "//":
+			panic(runtime.Errorf("division by zero"))
+		case "%":
+			panic(runtime.Errorf("modulo by zero"))
+		}
+	}
+
+	var res float64
+
+	switch op {
+	case "+":
+		res = l + r
 	case "-":
-		res = lf - rf
+		res = l - r
 	case "*":
-		res = lf * rf
+		res = l * r
 	case "/":
-		res = lf / rf
+		res = l / r
+	case "//":
+		res = math.Floor(l / r)
 	case "%":
-		res = math.Mod(lf, rf)
+		res = math.Mod(l, r)
+	case "**":
+		res = math.Pow(l, r)
 	default:
-		panic("unsupported arithmetic op: " + op)
+		panic(runtime.Errorf("unsupported arithmetic op: %s", op))
 	}
 
-	if lIsInt && rIsInt && res == math.Trunc(res) {
-		return &runtime.IntValue{Value: int(res)}
-	}
 	return &runtime.FloatValue{Value: res}
 }
 
+// compareNumbers orders two numbers: -1, 0 or +1. Ints compare exactly, even
+// big ones; as soon as a float is involved the comparison is done in floats.
+// NaN is unordered, reported as ok == false.
+func compareNumbers(left, right runtime.RuntimeValue) (order int, ok bool) {
+	if l, lok := left.(*runtime.IntValue); lok {
+		if r, rok := right.(*runtime.IntValue); rok {
+			switch {
+			case l.Value < r.Value:
+				return -1, true
+			case l.Value > r.Value:
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+
+	if isInteger(left) && isInteger(right) {
+		a, _ := runtime.BigInt(left)
+		b, _ := runtime.BigInt(right)
+		return a.Cmp(b), true
+	}
+
+	lf, rf := asFloat(left), asFloat(right)
+	switch {
+	case lf < rf:
+		return -1, true
+	case lf > rf:
+		return 1, true
+	case lf == rf:
+		return 0, true
+	}
+	return 0, false
+}
+
 func (interpreter *Interpreter) evalComparison(left, right runtime.RuntimeValue, op string) runtime.RuntimeValue {
 	if isNumber(left) && isNumber(right) {
-		lf := interpreter.asFloat(left)
-		rf := interpreter.asFloat(right)
+		order, ok := compareNumbers(left, right)
+		if !ok {
+			// NaN: only != holds
+			return &runtime.BooleanValue{Value: op == "!="}
+		}
 		switch op {
 		case "==":
-			return &runtime.BooleanValue{Value: lf == rf}
+			return &runtime.BooleanValue{Value: order == 0}
 		case "!=":
-			return &runtime.BooleanValue{Value: lf != rf}
+			return &runtime.BooleanValue{Value: order != 0}
 		case "<":
-			return &runtime.BooleanValue{Value: lf < rf}
+			return &runtime.BooleanValue{Value: order < 0}
 		case ">":
-			return &runtime.BooleanValue{Value: lf > rf}
+			return &runtime.BooleanValue{Value: order > 0}
 		case "<=":
-			return &runtime.BooleanValue{Value: lf <= rf}
+			return &runtime.BooleanValue{Value: order <= 0}
 		case ">=":
-			return &runtime.BooleanValue{Value: lf >= rf}
+			return &runtime.BooleanValue{Value: order >= 0}
 		}
 	}
 
@@ -102,5 +253,5 @@ func (interpreter *Interpreter) evalComparison(left, right runtime.RuntimeValue,
 		return &runtime.BooleanValue{Value: !runtimeEqual(left, right)}
 	}
 
-	panic("invalid comparison between types for operator: " + op)
+	panic(runtime.Errorf("invalid comparison between types for operator: %s", op))
 }
@@ -1,6 +1,7 @@
 package interpreter
 
 import (
+	"math/big"
 	"pcl/src/frontend/ast"
 	"pcl/src/runtime"
 )
@@ -12,9 +13,12 @@ func (interpreter *Interpreter) evalBinOp(binOpNode *ast.BinaryOpNode) runtime.R
 	if op == "&&" || op == "||" {
 		return interpreter.evalLogical(binOpNode, op)
 	}
+	if op == "??" {
+		return interpreter.evalNilCoalesce(binOpNode)
+	}
 
-	left := interpreter.Evaluate(binOpNode.Left)
-	right := interpreter.Evaluate(binOpNode.Right)
+	left := interpreter.evaluate(binOpNode.Left)
+	right := interpreter.evaluate(binOpNode.Right)
 
 	// bitwise
 	if op == "&" || op == "|" || op == "^" || op == "<<" || op == ">>" {
@@ -22,64 +26,96 @@ func (interpreter *Interpreter) evalBinOp(binOpNode *ast.BinaryOpNode) runtime.R
 	}
 
 	// arithmetic
-	if op == "+" || op == "-" || op == "*" || op == "/" || op == "%" {
+	if op == "+" || op == "-" || op == "*" || op == "/" || op == "%" || op == "**" || op == "//" {
 		return interpreter.evalArithmetic(left, right, op)
 	}
 
+	// membership
+	if op == "in" {
+		return interpreter.evalMembership(left, right)
+	}
+
 	// comparison
 	if op == "==" || op == "!=" || op == "<" || op == ">" || op == "<=" || op == ">=" {
 		return interpreter.evalComparison(left, right, op)
 	}
 
-	panic("unknown binary operator: " + op)
+	panic(runtime.Errorf("unknown binary operator: %s", op))
 }
 
+// && and || short-circuit and hand back one of their operands rather than a
+// bool: a || b is a if a is truthy, else b; a && b is a if a is falsy, else b
 func (interpreter *Interpreter) evalLogical(binOpNode *ast.BinaryOpNode, op string) runtime.RuntimeValue {
-	left := interpreter.Evaluate(binOpNode.Left)
-	lb, ok := left.(*runtime.BooleanValue)
-	if !ok {
-		panic("left operand of logical operator is not bool")
-	}
+	left := interpreter.evaluate(binOpNode.Left)
 
-	if op == "&&" {
-		if !lb.Value {
-			return &runtime.BooleanValue{Value: false}
-		}
-		right := interpreter.Evaluate(binOpNode.Right)
-		rb, ok := right.(*runtime.BooleanValue)
-		if !ok {
-			panic("right operand of logical operator is not bool")
-		}
-		return &runtime.BooleanValue{Value: lb.Value && rb.Value}
+	if runtime.Truthy(left) == (op == "||") {
+		return left
 	}
+	return interpreter.evaluate(binOpNode.Right)
+}
 
-	if lb.Value {
-		return &runtime.BooleanValue{Value: true}
-	}
+// a ?? b is a unless a is nil; unlike || it keeps false, 0 and ""
+func (interpreter *Interpreter) evalNilCoalesce(binOpNode *ast.BinaryOpNode) runtime.RuntimeValue {
+	left := interpreter.evaluate(binOpNode.Left)
 
-	right := interpreter.Evaluate(binOpNode.Right)
-	rb, ok := right.(*runtime.BooleanValue)
-	if !ok {
-		panic("right operand of logical operator is not bool")
+	if _, isNil := left.(*runtime.NilValue); !isNil {
+		return left
 	}
-	return &runtime.BooleanValue{Value: lb.Value || rb.Value}
+	return interpreter.evaluate(binOpNode.Right)
 }
 
 func (interpreter *Interpreter) evalBitwise(left, right runtime.RuntimeValue, op string) runtime.RuntimeValue {
-	lInt, lok := left.(*runtime.IntValue)
-	rInt, rok := right.(*runtime.IntValue)
-	if !lok || !rok {
-		panic("bitwise operators only work on ints")
+	if !isInteger(left) || !isInteger(right) {
+		panic(runtime.Errorf("bitwise operators only work on ints"))
 	}
 
+	lInt, lSmall := left.(*runtime.IntValue)
+	rInt, rSmall := right.(*runtime.IntValue)
+
+	if op == "<<" || op == ">>" {
+		if !rSmall || rInt.Value < 0 {
+			panic(runtime.Errorf("shift count must be a non-negative int"))
+		}
+		if rInt.Value > 1<<24 {
+			panic(runtime.Errorf("shift count %d is too large", rInt.Value))
+		}
+	}
+
+	// machine ints, unless a << would push bits off the top
+	if lSmall && rSmall {
+		switch op {
+		case "&":
+			return &runtime.IntValue{Value: lInt.Value & rInt.Value}
+		case "|":
+			return &runtime.IntValue{Value: lInt.Value | rInt.Value}
+		case "^":
+			return &runtime.IntValue{Value: lInt.Value ^ rInt.Value}
+		case ">>":
+			return &runtime.IntValue{Value: lInt.Value >> rInt.Value}
+		case "<<":
+			if rInt.Value < 63 {
+				if shifted := lInt.Value << rInt.Value; shifted>>rInt.Value == lInt.Value {
+					return &runtime.IntValue{Value: shifted}
+				}
+			}
+		}
+	}
+
+	a, _ := runtime.BigInt(left)
+	b, _ := runtime.BigInt(right)
+
 	switch op {
 	case "&":
-		return &runtime.IntValue{Value: lInt.Value & rInt.Value}
+		return runtime.NewInt(new(big.Int).And(a, b))
 	case "|":
-		return &runtime.IntValue{Value: lInt.Value | rInt.Value}
+		return runtime.NewInt(new(big.Int).Or(a, b))
 	case "^":
-		return &runtime.IntValue{Value: lInt.Value ^ rInt.Value}
+		return runtime.NewInt(new(big.Int).Xor(a, b))
+	case "<<":
+		return runtime.NewInt(new(big.Int).Lsh(a, uint(b.Int64())))
+	case ">>":
+		return runtime.NewInt(new(big.Int).Rsh(a, uint(b.Int64())))
 	default:
-		panic("unsupported bitwise op: " + op)
+		panic(runtime.Errorf("unsupported bitwise op: %s", op))
 	}
 }
@@ -0,0 +1,173 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"pcl/src/frontend/lexer"
+	"pcl/src/runtime"
+	"strconv"
+	"strings"
+)
+
+// registerBuiltins fills the global scope with the native functions every script can call
+func (interpreter *Interpreter) registerBuiltins() {
+	builtins := []*runtime.NativeFunctionValue{
+		{Name: "print", Arity: -1, Fn: interpreter.builtinPrint},
+		{Name: "println", Arity: -1, Fn: interpreter.builtinPrintln},
+		{Name: "input", Arity: -1, Fn: interpreter.builtinInput},
+		{Name: "len", Arity: 1, Fn: builtinLen},
+		{Name: "type", Arity: 1, Fn: builtinType},
+		{Name: "str", Arity: 1, Fn: builtinStr},
+		{Name: "int", Arity: 1, Fn: builtinInt},
+		{Name: "float", Arity: 1, Fn: builtinFloat},
+		{Name: "error", Arity: 1, Fn: builtinError},
+		{Name: "variants", Arity: 1, Fn: builtinVariants},
+		{Name: "tag", Arity: 1, Fn: builtinTag},
+		{Name: "payload", Arity: 1, Fn: builtinPayload},
+	}
+
+	for _, builtin := range builtins {
+		interpreter.globalScope.SetVariable(builtin.Name, builtin)
+	}
+}
+
+func displayArgs(args []runtime.RuntimeValue) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = runtime.Display(arg)
+	}
+	return strings.Join(parts, " ")
+}
+
+// print(a, b, ...) writes its arguments separated by spaces, without a newline
+func (interpreter *Interpreter) builtinPrint(args []runtime.RuntimeValue) runtime.RuntimeValue {
+	fmt.Fprint(interpreter.stdout, displayArgs(args))
+	return &runtime.NilValue{}
+}
+
+func (interpreter *Interpreter) builtinPrintln(args []runtime.RuntimeValue) runtime.RuntimeValue {
+	fmt.Fprintln(interpreter.stdout, displayArgs(args))
+	return &runtime.NilValue{}
+}
+
+// input([prompt]) reads one line from stdin, without the trailing newline
+func (interpreter *Interpreter) builtinInput(args []runtime.RuntimeValue) runtime.RuntimeValue {
+	if len(args) > 1 {
+		panic(runtime.Errorf("input expects at most 1 argument, got %d", len(args)))
+	}
+
+	if len(args) == 1 {
+		fmt.Fprint(interpreter.stdout, runtime.Display(args[0]))
+	}
+
+	line, err := interpreter.stdin.ReadString('\n')
+	if err != nil && line == "" {
+		return &runtime.NilValue{} // eof
+	}
+
+	return &runtime.StringValue{Value: strings.TrimRight(line, "\r\n")}
+}
+
+func builtinLen(args []runtime.RuntimeValue) runtime.RuntimeValue {
+	switch v := args[0].(type) {
+	case *runtime.StringValue:
+		return &runtime.IntValue{Value: len([]rune(v.Value))}
+	case *runtime.ArrayValue:
+		return &runtime.IntValue{Value: len(v.Elements)}
+	case *runtime.MapValue:
+		return &runtime.IntValue{Value: v.Len()}
+	default:
+		panic(runtime.Errorf("len expects a string, array or map, got %s", runtime.TypeName(v)))
+	}
+}
+
+func builtinType(args []runtime.RuntimeValue) runtime.RuntimeValue {
+	return &runtime.StringValue{Value: runtime.TypeName(args[0])}
+}
+
+func builtinStr(args []runtime.RuntimeValue) runtime.RuntimeValue {
+	return &runtime.StringValue{Value: runtime.Display(args[0])}
+}
+
+func builtinInt(args []runtime.RuntimeValue) runtime.RuntimeValue {
+	switch v := args[0].(type) {
+	case *runtime.IntValue, *runtime.BigIntValue:
+		return v
+	case *runtime.FloatValue:
+		if math.IsNaN(v.Value) || math.IsInf(v.Value, 0) {
+			panic(runtime.Errorf("cannot convert %s to int", runtime.Display(v)))
+		}
+		integer, _ := big.NewFloat(v.Value).Int(nil) // truncates toward zero
+		return runtime.NewInt(integer)
+	case *runtime.BooleanValue:
+		if v.Value {
+			return &runtime.IntValue{Value: 1}
+		}
+		return &runtime.IntValue{Value: 0}
+	case *runtime.StringValue:
+		// same spellings as int literals: 42, -7, 0xFF, 1_000
+		num, ok := lexer.ParseInt(strings.TrimSpace(v.Value))
+		if !ok {
+			panic(runtime.Errorf("cannot convert %q to int", v.Value))
+		}
+		return runtime.NewInt(num)
+	default:
+		panic(runtime.Errorf("cannot convert %s to int", runtime.TypeName(v)))
+	}
+}
+
+func builtinFloat(args []runtime.RuntimeValue) runtime.RuntimeValue {
+	switch v := args[0].(type) {
+	case *runtime.FloatValue:
+		return v
+	case *runtime.IntValue, *runtime.BigIntValue:
+		return &runtime.FloatValue{Value: asFloat(v)}
+	case *runtime.StringValue:
+		num, err := strconv.ParseFloat(strings.TrimSpace(v.Value), 64)
+		if err != nil {
+			panic(runtime.Errorf("cannot convert %q to float", v.Value))
+		}
+		return &runtime.FloatValue{Value: num}
+	default:
+		panic(runtime.Errorf("cannot convert %s to float", runtime.TypeName(v)))
+	}
+}
+
+// error(message) makes an error value for throw, like the ones catch receives
+func builtinError(args []runtime.RuntimeValue) runtime.RuntimeValue {
+	return &runtime.ErrorValue{Message: runtime.Display(args[0])}
+}
+
+// variants(Shape) lists every variant name of an enum, in declaration order
+func builtinVariants(args []runtime.RuntimeValue) runtime.RuntimeValue {
+	enum, ok := args[0].(*runtime.EnumValue)
+	if !ok {
+		panic(runtime.Errorf("variants expects an enum, got %s", runtime.TypeName(args[0])))
+	}
+
+	names := make([]runtime.RuntimeValue, len(enum.Variants))
+	for i, variant := range enum.Variants {
+		names[i] = &runtime.StringValue{Value: variant.Name}
+	}
+	return &runtime.ArrayValue{Elements: names}
+}
+
+// tag(Shape.Circle(1)) is "Circle"
+func builtinTag(args []runtime.RuntimeValue) runtime.RuntimeValue {
+	return &runtime.StringValue{Value: enumArg("tag", args[0]).Variant.Name}
+}
+
+// payload(Shape.Rect(1, 2)) is [1, 2], empty for variants without fields
+func builtinPayload(args []runtime.RuntimeValue) runtime.RuntimeValue {
+	value := enumArg("payload", args[0])
+	return &runtime.ArrayValue{Elements: append([]runtime.RuntimeValue{}, value.Payload...)}
+}
+
+func enumArg(name string, arg runtime.RuntimeValue) *runtime.EnumInstanceValue {
+	value, ok := arg.(*runtime.EnumInstanceValue)
+	if !ok {
+		panic(runtime.Errorf("%s expects an enum value, got %s", name, runtime.TypeName(arg)))
+	}
+	return value
+}
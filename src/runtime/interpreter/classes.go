@@ -0,0 +1,74 @@
+package interpreter
+
+import (
+	"pcl/src/frontend/ast"
+	"pcl/src/runtime"
+)
+
+// this and super are keywords, so scripts can never declare them: methods
+// are bound by wrapping their closure in a scope holding this, and methods
+// of a subclass close over one more scope holding super
+func (interpreter *Interpreter) evalClassDecl(node *ast.ClassDeclNode) runtime.RuntimeValue {
+	class := &runtime.ClassValue{Name: node.Name, Methods: map[string]*runtime.FunctionValue{}}
+	methodScope := interpreter.currentScope
+
+	if node.Superclass != nil {
+		superclass, ok := interpreter.evaluate(node.Superclass).(*runtime.ClassValue)
+		if !ok {
+			panic(runtime.Errorf("class %s can only extend a class", node.Name))
+		}
+		class.Superclass = superclass
+
+		methodScope = runtime.NewScope(methodScope)
+		methodScope.SetVariable("super", superclass)
+	}
+
+	for _, method := range node.Methods {
+		class.Methods[method.Name] = &runtime.FunctionValue{
+			Name:      node.Name + "." + method.Name,
+			Arguments: method.Arguments,
+			Body:      method.Body,
+			Scope:     methodScope,
+		}
+	}
+
+	return interpreter.currentScope.SetVariable(node.Name, class)
+}
+
+// calling a class: a fresh instance, initialised by init if the chain has one
+func (interpreter *Interpreter) instantiate(class *runtime.ClassValue, args []runtime.RuntimeValue) runtime.RuntimeValue {
+	instance := runtime.NewInstance(class)
+
+	init, ok := class.FindMethod("init")
+	if !ok {
+		if len(args) != 0 {
+			panic(runtime.Errorf("%s expects 0 arguments, got %d", class.Name, len(args)))
+		}
+		return instance
+	}
+
+	// whatever init returns, the call evaluates to the instance
+	interpreter.callUserFunction(instance.BoundMethod(init), args)
+	return instance
+}
+
+func (interpreter *Interpreter) evalThis(node *ast.ThisNode) runtime.RuntimeValue {
+	if !interpreter.currentScope.HasVariable("this") {
+		panic(runtime.Errorf("'this' used outside of a method"))
+	}
+	return interpreter.currentScope.GetVariable("this")
+}
+
+func (interpreter *Interpreter) evalSuper(node *ast.SuperNode) runtime.RuntimeValue {
+	if !interpreter.currentScope.HasVariable("super") {
+		panic(runtime.Errorf("'super' used outside of a subclass method"))
+	}
+	superclass := interpreter.currentScope.GetVariable("super").(*runtime.ClassValue)
+	instance := interpreter.evalThis(&ast.ThisNode{}).(*runtime.InstanceValue)
+
+	method, ok := superclass.FindMethod(node.Method)
+	if !ok {
+		panic(runtime.Errorf("%s has no method '%s'", superclass.Name, node.Method))
+	}
+	return instance.BoundMethod(method)
+}
@@ -0,0 +1,201 @@
+package interpreter
+
+import (
+	"pcl/src/frontend/ast"
+	"pcl/src/runtime"
+	"strings"
+)
+
+func (interpreter *Interpreter) evalArrayLiteral(node *ast.ArrayLiteralNode) runtime.RuntimeValue {
+	elements := make([]runtime.RuntimeValue, len(node.Elements))
+	for i, element := range node.Elements {
+		elements[i] = interpreter.evaluate(element)
+	}
+
+	return &runtime.ArrayValue{Elements: elements}
+}
+
+func (interpreter *Interpreter) evalMapLiteral(node *ast.MapLiteralNode) runtime.RuntimeValue {
+	mapValue := runtime.NewMapValue()
+	for i, key := range node.Keys {
+		mapValue.Set(interpreter.evaluate(key), interpreter.evaluate(node.Values[i]))
+	}
+
+	return mapValue
+}
+
+func (interpreter *Interpreter) evalIndex(node *ast.IndexNode) runtime.RuntimeValue {
+	object := interpreter.evaluate(node.Object)
+	if object == shortCircuit {
+		return object
+	}
+	index := interpreter.evaluate(node.Index)
+
+	switch obj := object.(type) {
+	case *runtime.ArrayValue:
+		return obj.Elements[resolveIndex(index, len(obj.Elements))]
+	case *runtime.StringValue:
+		chars := []rune(obj.Value)
+		return &runtime.StringValue{Value: string(chars[resolveIndex(index, len(chars))])}
+	case *runtime.MapValue:
+		// missing keys read as nil, use 'in' to tell them apart from stored nils
+		if value, ok := obj.Get(index); ok {
+			return value
+		}
+		return &runtime.NilValue{}
+	default:
+		panic(runtime.Errorf("value is not indexable"))
+	}
+}
+
+func (interpreter *Interpreter) evalSlice(node *ast.SliceNode) runtime.RuntimeValue {
+	object := interpreter.evaluate(node.Object)
+	if object == shortCircuit {
+		return object
+	}
+
+	switch obj := object.(type) {
+	case *runtime.ArrayValue:
+		start, end := interpreter.sliceBounds(node, len(obj.Elements))
+		elements := append([]runtime.RuntimeValue(nil), obj.Elements[start:end]...)
+		return &runtime.ArrayValue{Elements: elements}
+	case *runtime.StringValue:
+		chars := []rune(obj.Value)
+		start, end := interpreter.sliceBounds(node, len(chars))
+		return &runtime.StringValue{Value: string(chars[start:end])}
+	default:
+		panic(runtime.Errorf("value is not sliceable"))
+	}
+}
+
+// xs[i] = v and its compound forms
+func (interpreter *Interpreter) assignIndex(node *ast.IndexNode, operator string, value runtime.RuntimeValue) runtime.RuntimeValue {
+	object := interpreter.evaluate(node.Object)
+	index := interpreter.evaluate(node.Index)
+
+	switch obj := object.(type) {
+	case *runtime.ArrayValue:
+		i := resolveIndex(index, len(obj.Elements))
+		if operator != "=" {
+			value = interpreter.evalArithmetic(obj.Elements[i], value, strings.TrimSuffix(operator, "="))
+		}
+		obj.Elements[i] = value
+		return value
+	case *runtime.MapValue:
+		if operator != "=" {
+			current, ok := obj.Get(index)
+			if !ok {
+				panic(runtime.Errorf("key not found for compound assignment"))
+			}
+			value = interpreter.evalArithmetic(current, value, strings.TrimSuffix(operator, "="))
+		}
+		obj.Set(index, value)
+		return value
+	case *runtime.StringValue:
+		panic(runtime.Errorf("strings are immutable"))
+	default:
+		panic(runtime.Errorf("value does not support index assignment"))
+	}
+}
+
+// delete m[k]; removes a map entry or an array element
+func (interpreter *Interpreter) evalDelete(node *ast.DeleteNode) runtime.RuntimeValue {
+	object := interpreter.evaluate(node.Target.Object)
+	index := interpreter.evaluate(node.Target.Index)
+
+	switch obj := object.(type) {
+	case *runtime.MapValue:
+		obj.Delete(index)
+	case *runtime.ArrayValue:
+		i := resolveIndex(index, len(obj.Elements))
+		obj.Elements = append(obj.Elements[:i], obj.Elements[i+1:]...)
+	default:
+		panic(runtime.Errorf("value does not support delete"))
+	}
+
+	return &runtime.NilValue{}
+}
+
+// 'needle in haystack' for maps (keys), arrays (elements) and strings (substrings)
+func (interpreter *Interpreter) evalMembership(needle, haystack runtime.RuntimeValue) runtime.RuntimeValue {
+	switch h := haystack.(type) {
+	case *runtime.MapValue:
+		return &runtime.BooleanValue{Value: h.Has(needle)}
+	case *runtime.ArrayValue:
+		for _, element := range h.Elements {
+			if runtimeEqual(needle, element) {
+				return &runtime.BooleanValue{Value: true}
+			}
+		}
+		return &runtime.BooleanValue{Value: false}
+	case *runtime.StringValue:
+		n, ok := needle.(*runtime.StringValue)
+		if !ok {
+			panic(runtime.Errorf("left operand of 'in' must be a string when searching a string"))
+		}
+		return &runtime.BooleanValue{Value: strings.Contains(h.Value, n.Value)}
+	default:
+		panic(runtime.Errorf("right operand of 'in' is not a collection"))
+	}
+}
+
+// resolveIndex turns a (possibly negative) index into a checked slice position
+func resolveIndex(index runtime.RuntimeValue, length int) int {
+	if big, ok := index.(*runtime.BigIntValue); ok {
+		panic(runtime.Errorf("index %s out of bounds for length %d", big.Value, length))
+	}
+
+	i, ok := index.(*runtime.IntValue)
+	if !ok {
+		panic(runtime.Errorf("index must be an int"))
+	}
+
+	pos := i.Value
+	if pos < 0 {
+		pos += length
+	}
+
+	if pos < 0 || pos >= length {
+		panic(runtime.Errorf("index %d out of bounds for length %d", i.Value, length))
+	}
+
+	return pos
+}
+
+// sliceBounds evaluates [start:end], allowing negatives and clamping to the length
+func (interpreter *Interpreter) sliceBounds(node *ast.SliceNode, length int) (int, int) {
+	bound := func(expr ast.ASTNode, fallback int) int {
+		if expr == nil {
+			return fallback
+		}
+
+		value := interpreter.evaluate(expr)
+
+		// a big int is past either end, wherever it points
+		if big, ok := value.(*runtime.BigIntValue); ok {
+			if big.Value.Sign() < 0 {
+				return 0
+			}
+			return length
+		}
+
+		i, ok := value.(*runtime.IntValue)
+		if !ok {
+			panic(runtime.Errorf("slice bounds must be ints"))
+		}
+
+		pos := i.Value
+		if pos < 0 {
+			pos += length
+		}
+		return max(0, min(pos, length))
+	}
+
+	start := bound(node.Start, 0)
+	end := bound(node.End, length)
+	if end < start {
+		end = start
+	}
+
+	return start, end
+}
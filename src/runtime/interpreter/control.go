@@ -0,0 +1,97 @@
+package interpreter
+
+import (
+	"pcl/src/frontend/ast"
+	"pcl/src/runtime"
+)
+
+func (interpreter *Interpreter) evalCondition(node ast.ASTNode) bool {
+	return runtime.Truthy(interpreter.evaluate(node))
+}
+
+func (interpreter *Interpreter) evalIf(node *ast.IfNode) runtime.RuntimeValue {
+	if interpreter.evalCondition(node.Condition) {
+		// a ReturnValue from the branch is handed straight back so the
+		// enclosing evalBody / evalFuncCall can unwind it
+		return interpreter.evalBody(node.Consequent)
+	}
+
+	if node.Alternate != nil {
+		return interpreter.evaluate(node.Alternate)
+	}
+
+	return &runtime.NilValue{}
+}
+
+func (interpreter *Interpreter) evalWhile(node *ast.WhileNode) runtime.RuntimeValue {
+	for interpreter.evalCondition(node.Condition) {
+		result := interpreter.evalBody(node.Body)
+
+		switch result.(type) {
+		case *runtime.BreakValue:
+			return &runtime.NilValue{}
+		case *runtime.ReturnValue:
+			return result
+		}
+	}
+
+	return &runtime.NilValue{}
+}
+
+func (interpreter *Interpreter) evalFor(node *ast.ForNode) runtime.RuntimeValue {
+	// the init variable lives in its own scope wrapping the whole loop
+	interpreter.EnterScope()
+	defer interpreter.ExitScope()
+
+	if node.Init != nil {
+		interpreter.evaluate(node.Init)
+	}
+
+	for node.Condition == nil || interpreter.evalCondition(node.Condition) {
+		result := interpreter.evalBody(node.Body)
+
+		switch result.(type) {
+		case *runtime.BreakValue:
+			return &runtime.NilValue{}
+		case *runtime.ReturnValue:
+			return result
+		}
+
+		if node.Update != nil {
+			interpreter.evaluate(node.Update)
+		}
+	}
+
+	return &runtime.NilValue{}
+}
+
+func (interpreter *Interpreter) evalForIn(node *ast.ForInNode) runtime.RuntimeValue {
+	iterable, ok := interpreter.evaluate(node.Iterable).(runtime.Iterable)
+	if !ok {
+		panic(runtime.Errorf("value is not iterable"))
+	}
+
+	for _, item := range iterable.Iterate() {
+		// fresh scope per iteration so each pass gets its own loop variable
+		interpreter.EnterScope()
+		interpreter.currentScope.SetVariable(node.Variable, item)
+		result := interpreter.evalBody(node.Body)
+		interpreter.ExitScope()
+
+		switch result.(type) {
+		case *runtime.BreakValue:
+			return &runtime.NilValue{}
+		case *runtime.ReturnValue:
+			return result
+		}
+	}
+
+	return &runtime.NilValue{}
+}
+
+func (interpreter *Interpreter) evalTernary(node *ast.TernaryNode) runtime.RuntimeValue {
+	if interpreter.evalCondition(node.Condition) {
+		return interpreter.evaluate(node.Consequent)
+	}
+	return interpreter.evaluate(node.Alternate)
+}
@@ -0,0 +1,32 @@
+package interpreter
+
+import (
+	"fmt"
+	"pcl/src/frontend/ast"
+	"pcl/src/frontend/source"
+	"pcl/src/runtime"
+)
+
+// variants are only reachable through the enum, Shape.Circle, so declaring
+// one never shadows an existing name
+func (interpreter *Interpreter) evalEnumDecl(node *ast.EnumDeclNode) runtime.RuntimeValue {
+	enum := runtime.NewEnum(node.Name)
+	for _, variant := range node.Variants {
+		enum.AddVariant(variant.Name, variant.Fields)
+	}
+	return interpreter.currentScope.SetVariable(node.Name, enum)
+}
+
+// Shape.Cirle, with a did-you-mean over the enum's own variants
+func unknownVariant(enum *runtime.EnumValue, name string) *runtime.RuntimeError {
+	names := make([]string, len(enum.Variants))
+	for i, variant := range enum.Variants {
+		names[i] = variant.Name
+	}
+
+	err := runtime.Errorf("%s has no variant '%s'", enum.Name, name)
+	if suggestion, ok := source.Closest(name, names); ok {
+		err.Hint = fmt.Sprintf("did you mean '%s'?", suggestion)
+	}
+	return err
+}
@@ -1,41 +1,152 @@
 package interpreter
 
 import (
+	"math/big"
 	"pcl/src/frontend/ast"
 	"pcl/src/runtime"
-	"strconv"
 )
 
-func (interpreter *Interpreter) Evaluate(node ast.ASTNode) runtime.RuntimeValue {
+// Evaluate runs a whole program (or one repl line). Runtime failures come back
+// as a *runtime.RuntimeError pointing at the node that failed, with the PCL call
+// stack at that moment; the interpreter is left usable for the next call.
+func (interpreter *Interpreter) Evaluate(node ast.ASTNode) (result runtime.RuntimeValue, err error) {
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+
+		runtimeErr, ok := recovered.(*runtime.RuntimeError)
+		if !ok {
+			runtimeErr = runtime.Errorf("internal error: %v", recovered)
+		}
+		interpreter.locate(runtimeErr)
+
+		// unwind whatever was in flight so the repl can keep going
+		interpreter.currentScope = interpreter.globalScope
+		interpreter.callStack = interpreter.callStack[:0]
+		interpreter.node = nil
+
+		result, err = nil, runtimeErr
+	}()
+
+	return interpreter.evaluate(node), nil
+}
+
+// locate fills in where an error happened from the interpreter's state at the
+// moment it surfaced; errors that already know their position keep it
+func (interpreter *Interpreter) locate(err *runtime.RuntimeError) {
+	if !err.Located() && interpreter.node != nil {
+		err.Span = interpreter.node.Position()
+	}
+	if err.Stack == nil {
+		err.Stack = append([]runtime.StackFrame{}, interpreter.callStack...)
+	}
+}
+
+// evaluate remembers which node is being evaluated so errors raised anywhere
+// below it can be located. It is deliberately not restored on panic: the
+// innermost node is the one to blame.
+func (interpreter *Interpreter) evaluate(node ast.ASTNode) runtime.RuntimeValue {
+	parent := interpreter.node
+	interpreter.node = node
+
+	result := interpreter.dispatch(node)
+
+	interpreter.node = parent
+	return result
+}
+
+func (interpreter *Interpreter) dispatch(node ast.ASTNode) runtime.RuntimeValue {
 	switch node := node.(type) {
-		case *ast.ProgramNode:
-			return interpreter.evalProgram(node)
-		case *ast.BodyNode:
-			return interpreter.evalBody(node)
-		case *ast.BinaryOpNode:
-			return interpreter.evalBinOp(node)
-		case *ast.VarDeclNode:
-			return interpreter.evalVarDecl(node)
-		case *ast.AssignmentNode:
-			return interpreter.evalAssignment(node)
-		case *ast.UnaryOpNode:
-			return interpreter.evalUnary(node)
-		case *ast.IdentifierNode:
-			return interpreter.evalIdentifier(node)
-		case *ast.FunctionCallNode:
-			return interpreter.evalFuncCall(node)
-		case *ast.FunctionLiteralNode:
-			return &runtime.FunctionValue{Arguments: node.Arguments, Body: node.Body}
-		case *ast.ReturnNode:
-			return &runtime.ReturnValue{Value: interpreter.Evaluate(node.Value)}
-		case *ast.LiteralNode[float64]:
-			return &runtime.FloatValue{Value: node.Value}
-		case *ast.LiteralNode[int]:
-			return &runtime.IntValue{Value: node.Value}
-		case *ast.LiteralNode[string]:
-			return &runtime.StringValue{Value: node.Value}
-		default:
-			panic("unsupported AST node type: " + strconv.Itoa(int(node.Type())))
+	case *ast.ProgramNode:
+		return interpreter.evalProgram(node)
+	case *ast.BodyNode:
+		return interpreter.evalBody(node)
+	case *ast.BinaryOpNode:
+		return interpreter.evalBinOp(node)
+	case *ast.VarDeclNode:
+		return interpreter.evalVarDecl(node)
+	case *ast.AssignmentNode:
+		return interpreter.evalAssignment(node)
+	case *ast.ParallelAssignmentNode:
+		return interpreter.evalParallelAssignment(node)
+	case *ast.UnaryOpNode:
+		return interpreter.evalUnary(node)
+	case *ast.TernaryNode:
+		return interpreter.evalTernary(node)
+	case *ast.MatchNode:
+		return interpreter.evalMatch(node)
+	case *ast.IdentifierNode:
+		return interpreter.evalIdentifier(node)
+	case *ast.FunctionCallNode:
+		return interpreter.evalFuncCall(node)
+	case *ast.MethodCallNode:
+		return interpreter.evalMethodCall(node)
+	case *ast.MemberNode:
+		return interpreter.evalMember(node)
+	case *ast.OptionalChainNode:
+		return interpreter.evalOptionalChain(node)
+	case *ast.FunctionLiteralNode:
+		return &runtime.FunctionValue{
+			Name:      node.Name,
+			Arguments: node.Arguments,
+			Body:      node.Body,
+			Scope:     interpreter.currentScope,
+		}
+	case *ast.ReturnNode:
+		if node.Value == nil {
+			return &runtime.ReturnValue{Value: &runtime.NilValue{}}
+		}
+		return &runtime.ReturnValue{Value: interpreter.evaluate(node.Value)}
+	case *ast.IfNode:
+		return interpreter.evalIf(node)
+	case *ast.WhileNode:
+		return interpreter.evalWhile(node)
+	case *ast.ForNode:
+		return interpreter.evalFor(node)
+	case *ast.ForInNode:
+		return interpreter.evalForIn(node)
+	case *ast.DeleteNode:
+		return interpreter.evalDelete(node)
+	case *ast.BreakNode:
+		return &runtime.BreakValue{}
+	case *ast.ContinueNode:
+		return &runtime.ContinueValue{}
+	case *ast.StructDeclNode:
+		return interpreter.currentScope.SetVariable(node.Name, &runtime.StructValue{Name: node.Name, Fields: node.Fields})
+	case *ast.EnumDeclNode:
+		return interpreter.evalEnumDecl(node)
+	case *ast.ClassDeclNode:
+		return interpreter.evalClassDecl(node)
+	case *ast.ThisNode:
+		return interpreter.evalThis(node)
+	case *ast.SuperNode:
+		return interpreter.evalSuper(node)
+	case *ast.TryNode:
+		return interpreter.evalTry(node)
+	case *ast.ThrowNode:
+		return interpreter.evalThrow(node)
+	case *ast.ArrayLiteralNode:
+		return interpreter.evalArrayLiteral(node)
+	case *ast.MapLiteralNode:
+		return interpreter.evalMapLiteral(node)
+	case *ast.IndexNode:
+		return interpreter.evalIndex(node)
+	case *ast.SliceNode:
+		return interpreter.evalSlice(node)
+	case *ast.LiteralNode[float64]:
+		return &runtime.FloatValue{Value: node.Value}
+	case *ast.LiteralNode[int]:
+		return &runtime.IntValue{Value: node.Value}
+	case *ast.LiteralNode[*big.Int]:
+		return &runtime.BigIntValue{Value: node.Value}
+	case *ast.LiteralNode[string]:
+		return &runtime.StringValue{Value: node.Value}
+	case *ast.InterpolationNode:
+		return interpreter.evalInterpolation(node)
+	default:
+		panic(runtime.Errorf("unsupported AST node type: %d", node.Type()))
 	}
 }
 
@@ -43,29 +154,35 @@ func (interpreter *Interpreter) evalProgram(programNode *ast.ProgramNode) runtim
 	var lastVal runtime.RuntimeValue = &runtime.NilValue{}
 
 	for _, stmt := range programNode.Statements {
-		lastVal = interpreter.Evaluate(stmt)
+		lastVal = interpreter.evaluate(stmt)
 
-		if _, ok := lastVal.(*runtime.ReturnValue); ok {
-            panic("return outside function")
-        }
+		// blame the statement, not the whole program
+		switch lastVal.(type) {
+		case *runtime.ReturnValue:
+			panic(&runtime.RuntimeError{Message: "return outside function", Span: stmt.Position()})
+		case *runtime.BreakValue:
+			panic(&runtime.RuntimeError{Message: "break outside loop", Span: stmt.Position()})
+		case *runtime.ContinueValue:
+			panic(&runtime.RuntimeError{Message: "continue outside loop", Span: stmt.Position()})
+		}
 	}
 
 	return lastVal
 }
 
 func (interpreter *Interpreter) evalBody(blockNode *ast.BodyNode) runtime.RuntimeValue {
-    interpreter.EnterScope()
-    defer interpreter.ExitScope()
+	interpreter.EnterScope()
+	defer interpreter.ExitScope()
 
-	var result runtime.RuntimeValue
+	var result runtime.RuntimeValue = &runtime.NilValue{}
 
-    for _, stmt := range blockNode.Statements {
-        result = interpreter.Evaluate(stmt)
+	for _, stmt := range blockNode.Statements {
+		result = interpreter.evaluate(stmt)
 
-        if _, ok := result.(*runtime.ReturnValue); ok {
-            return result
-        }
-    }
+		if isControlSignal(result) {
+			return result
+		}
+	}
 
-    return result
+	return result
 }
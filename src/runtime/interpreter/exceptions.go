@@ -0,0 +1,83 @@
+package interpreter
+
+import (
+	"pcl/src/frontend/ast"
+	"pcl/src/runtime"
+)
+
+func (interpreter *Interpreter) evalTry(node *ast.TryNode) runtime.RuntimeValue {
+	result, thrown := interpreter.protect(func() runtime.RuntimeValue {
+		return interpreter.evaluate(node.Body)
+	})
+
+	if thrown != nil && node.Catch != nil {
+		caught := thrown.Caught()
+		result, thrown = interpreter.protect(func() runtime.RuntimeValue {
+			interpreter.EnterScope()
+			defer interpreter.ExitScope()
+
+			interpreter.currentScope.SetVariable(node.CatchName, caught)
+			return interpreter.evaluate(node.Catch)
+		})
+	}
+
+	if node.Finally != nil {
+		// a return / break / continue in finally wins over whatever was in flight,
+		// including an error nobody caught
+		if final := interpreter.evaluate(node.Finally); isControlSignal(final) {
+			return final
+		}
+	}
+
+	if thrown != nil {
+		panic(thrown) // already located, keeps its original position and stack
+	}
+
+	return result
+}
+
+// protect runs fn and hands back any runtime error it raised instead of letting
+// it unwind further, with the interpreter put back the way it was before fn
+func (interpreter *Interpreter) protect(fn func() runtime.RuntimeValue) (result runtime.RuntimeValue, thrown *runtime.RuntimeError) {
+	scope := interpreter.currentScope
+	depth := len(interpreter.callStack)
+	node := interpreter.node
+
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+
+		err, ok := recovered.(*runtime.RuntimeError)
+		if !ok {
+			panic(recovered) // a bug in the interpreter, not something scripts should catch
+		}
+		interpreter.locate(err)
+
+		interpreter.currentScope = scope
+		interpreter.callStack = interpreter.callStack[:depth]
+		interpreter.node = node
+
+		result, thrown = nil, err
+	}()
+
+	return fn(), nil
+}
+
+// throw accepts any value; error values remember where they were first thrown
+func (interpreter *Interpreter) evalThrow(node *ast.ThrowNode) runtime.RuntimeValue {
+	value := interpreter.evaluate(node.Value)
+
+	thrown := &runtime.RuntimeError{Message: runtime.Display(value), Span: node.Position(), Value: value}
+
+	if errorValue, ok := value.(*runtime.ErrorValue); ok {
+		if errorValue.Span.Line == 0 {
+			errorValue.Span = node.Position()
+		}
+		thrown.Message = errorValue.Message
+		thrown.Span = errorValue.Span
+	}
+
+	panic(thrown)
+}
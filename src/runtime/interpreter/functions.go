@@ -3,46 +3,97 @@ package interpreter
 import (
 	"pcl/src/frontend/ast"
 	"pcl/src/runtime"
-	"strconv"
 )
 
-
 func (interpreter *Interpreter) evalFuncCall(node *ast.FunctionCallNode) runtime.RuntimeValue {
-    funcVal := interpreter.Evaluate(node.Callee)
+	funcVal := interpreter.evaluate(node.Callee)
+	if skipChain(funcVal, node.Optional) {
+		return shortCircuit
+	}
+
+	return interpreter.callFunction(funcVal, interpreter.evalArguments(node.Arguments))
+}
+
+func (interpreter *Interpreter) evalArguments(nodes []ast.ASTNode) []runtime.RuntimeValue {
+	args := make([]runtime.RuntimeValue, len(nodes))
+	for i, arg := range nodes {
+		args[i] = interpreter.evaluate(arg)
+	}
+	return args
+}
+
+// callFunction invokes any callable runtime value with already evaluated arguments
+func (interpreter *Interpreter) callFunction(callee runtime.RuntimeValue, args []runtime.RuntimeValue) runtime.RuntimeValue {
+	switch function := callee.(type) {
+	case *runtime.FunctionValue:
+		return interpreter.callUserFunction(function, args)
+	case *runtime.NativeFunctionValue:
+		if function.Arity >= 0 && len(args) != function.Arity {
+			panic(runtime.Errorf("%s expects %d arguments, got %d", function.Name, function.Arity, len(args)))
+		}
+		return function.Fn(args)
+	case *runtime.StructValue:
+		// the struct itself is the constructor: Point(1, 2)
+		if len(args) != len(function.Fields) {
+			panic(runtime.Errorf("%s expects %d arguments, got %d", function.Name, len(function.Fields), len(args)))
+		}
+		return function.New(args)
+	case *runtime.EnumVariantValue:
+		if len(args) != len(function.Fields) {
+			panic(runtime.Errorf("%s expects %d arguments, got %d", function.FullName(), len(function.Fields), len(args)))
+		}
+		return function.New(args)
+	case *runtime.ClassValue:
+		return interpreter.instantiate(function, args)
+	default:
+		panic(runtime.Errorf("cannot call non-function value of type %s", runtime.TypeName(callee)))
+	}
+}
 
-    function, ok := funcVal.(*runtime.FunctionValue)
-    if !ok {
-        panic("cannot call non-function value")
-    }
+func (interpreter *Interpreter) callUserFunction(function *runtime.FunctionValue, args []runtime.RuntimeValue) runtime.RuntimeValue {
+	if len(args) != len(function.Arguments) {
+		panic(runtime.Errorf("%s expects %d arguments, got %d", functionName(function), len(function.Arguments), len(args)))
+	}
 
-    args := make([]runtime.RuntimeValue, len(node.Arguments))
-    for i, arg := range node.Arguments {
-        args[i] = interpreter.Evaluate(arg)
-    }
+	if len(interpreter.callStack) >= maxCallDepth {
+		panic(runtime.Errorf("maximum call depth of %d exceeded", maxCallDepth))
+	}
 
-    if len(args) != len(function.Arguments) {
-        panic("function expects " +
-            strconv.Itoa(len(function.Arguments)) +
-            " arguments, got " +
-            strconv.Itoa(len(args)))
-    }
+	// the current node is the call expression (or the builtin calling back into pcl)
+	interpreter.callStack = append(interpreter.callStack, runtime.StackFrame{
+		Function: functionName(function),
+		CallSite: interpreter.node.Position(),
+	})
 
-    prevScope := interpreter.currentScope
-    interpreter.currentScope = runtime.NewScope(prevScope)
+	// lexical scoping: the call scope hangs off the closure, not the caller
+	prevScope := interpreter.currentScope
+	interpreter.currentScope = runtime.NewScope(function.Scope)
 
-    for i, param := range function.Arguments {
-        interpreter.currentScope.SetVariable(param, args[i])
-    }
+	for i, param := range function.Arguments {
+		interpreter.currentScope.SetVariable(param, args[i])
+	}
 
-    result := interpreter.evalBody(function.Body)
+	result := interpreter.evalBody(function.Body)
 
-    interpreter.currentScope = prevScope
+	interpreter.currentScope = prevScope
+	interpreter.callStack = interpreter.callStack[:len(interpreter.callStack)-1]
 
-    // this part is the money shot brochacho
-    if ret, ok := result.(*runtime.ReturnValue); ok {
-        return ret.Value
-    }
+	// this part is the money shot brochacho
+	switch signal := result.(type) {
+	case *runtime.ReturnValue:
+		return signal.Value
+	case *runtime.BreakValue:
+		panic(runtime.Errorf("break outside loop"))
+	case *runtime.ContinueValue:
+		panic(runtime.Errorf("continue outside loop"))
+	}
 
-    return result
+	return result
 }
 
+func functionName(function *runtime.FunctionValue) string {
+	if function.Name == "" {
+		return "<anonymous>"
+	}
+	return function.Name
+}
@@ -1,44 +1,84 @@
 package interpreter
 
-import "pcl/src/runtime"
+import (
+	"math/big"
+	"pcl/src/runtime"
+)
 
-
-func (interpreter *Interpreter) asFloat(val runtime.RuntimeValue) float64 {
+func asFloat(val runtime.RuntimeValue) float64 {
 	switch v := val.(type) {
 	case *runtime.IntValue:
 		return float64(v.Value)
+	case *runtime.BigIntValue:
+		f, _ := new(big.Float).SetInt(v.Value).Float64()
+		return f
 	case *runtime.FloatValue:
 		return v.Value
 	default:
-		panic("operand is not a number")
+		panic(runtime.Errorf("operand is not a number"))
 	}
 }
 
 func isNumber(val runtime.RuntimeValue) bool {
 	switch val.(type) {
-		case *runtime.IntValue, *runtime.FloatValue:
-			return true
-		default:
-			return false
+	case *runtime.IntValue, *runtime.BigIntValue, *runtime.FloatValue:
+		return true
+	default:
+		return false
+	}
+}
+
+func isInteger(val runtime.RuntimeValue) bool {
+	switch val.(type) {
+	case *runtime.IntValue, *runtime.BigIntValue:
+		return true
+	default:
+		return false
+	}
+}
+
+// return, break and continue all unwind through evalBody untouched
+func isControlSignal(val runtime.RuntimeValue) bool {
+	switch val.(type) {
+	case *runtime.ReturnValue, *runtime.BreakValue, *runtime.ContinueValue:
+		return true
+	default:
+		return false
 	}
 }
 
 func runtimeEqual(a, b runtime.RuntimeValue) bool {
-	switch aa := a.(type) {
-	case *runtime.IntValue:
-		switch bb := b.(type) {
-		case *runtime.IntValue:
-			return aa.Value == bb.Value
-		case *runtime.FloatValue:
-			return float64(aa.Value) == bb.Value
+	return deepEqual(a, b, nil)
+}
+
+// pairs of containers already being compared further up, a pair met again
+// is taken as equal so cyclic values end instead of recursing forever
+type comparing map[[2]runtime.RuntimeValue]bool
+
+func deepEqual(a, b runtime.RuntimeValue, seen comparing) bool {
+	// ints, big ints and floats compare by value across representations
+	if isNumber(a) && isNumber(b) {
+		order, ok := compareNumbers(a, b)
+		return ok && order == 0
+	}
+
+	if a == b {
+		return true
+	}
+
+	switch a.(type) {
+	case *runtime.ArrayValue, *runtime.MapValue, *runtime.StructInstanceValue:
+		pair := [2]runtime.RuntimeValue{a, b}
+		if seen[pair] {
+			return true
 		}
-	case *runtime.FloatValue:
-		switch bb := b.(type) {
-		case *runtime.FloatValue:
-			return aa.Value == bb.Value
-		case *runtime.IntValue:
-			return aa.Value == float64(bb.Value)
+		if seen == nil {
+			seen = comparing{}
 		}
+		seen[pair] = true
+	}
+
+	switch aa := a.(type) {
 	case *runtime.StringValue:
 		if bb, ok := b.(*runtime.StringValue); ok {
 			return aa.Value == bb.Value
@@ -50,6 +90,58 @@ func runtimeEqual(a, b runtime.RuntimeValue) bool {
 	case *runtime.NilValue:
 		_, ok := b.(*runtime.NilValue)
 		return ok
+	case *runtime.EnumInstanceValue:
+		// same tag and equal payloads
+		bb, ok := b.(*runtime.EnumInstanceValue)
+		if !ok || aa.Variant != bb.Variant {
+			return false
+		}
+		for i := range aa.Payload {
+			if !deepEqual(aa.Payload[i], bb.Payload[i], seen) {
+				return false
+			}
+		}
+		return true
+	case *runtime.InstanceValue, *runtime.ClassValue, *runtime.StructValue, *runtime.ErrorValue,
+		*runtime.EnumValue, *runtime.EnumVariantValue,
+		*runtime.FunctionValue, *runtime.NativeFunctionValue:
+		return a == b // objects are equal only to themselves
+	case *runtime.StructInstanceValue:
+		// same struct and equal fields, not necessarily the same instance
+		bb, ok := b.(*runtime.StructInstanceValue)
+		if !ok || aa.Struct != bb.Struct {
+			return false
+		}
+		for i := range aa.Values {
+			if !deepEqual(aa.Values[i], bb.Values[i], seen) {
+				return false
+			}
+		}
+		return true
+	case *runtime.ArrayValue:
+		bb, ok := b.(*runtime.ArrayValue)
+		if !ok || len(aa.Elements) != len(bb.Elements) {
+			return false
+		}
+		for i := range aa.Elements {
+			if !deepEqual(aa.Elements[i], bb.Elements[i], seen) {
+				return false
+			}
+		}
+		return true
+	case *runtime.MapValue:
+		bb, ok := b.(*runtime.MapValue)
+		if !ok || aa.Len() != bb.Len() {
+			return false
+		}
+		for _, key := range aa.Keys() {
+			av, _ := aa.Get(key)
+			bv, found := bb.Get(key)
+			if !found || !deepEqual(av, bv, seen) {
+				return false
+			}
+		}
+		return true
 	}
 	return false
 }
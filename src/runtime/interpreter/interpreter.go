@@ -1,10 +1,27 @@
 package interpreter
 
-import "pcl/src/runtime"
+import (
+	"bufio"
+	"io"
+	"os"
+	"pcl/src/frontend/ast"
+	"pcl/src/runtime"
+)
+
+// deep enough for real recursion, shallow enough to fail before the go stack does
+const maxCallDepth = 5000
 
 type Interpreter struct {
 	globalScope  *runtime.Scope
 	currentScope *runtime.Scope
+
+	// for error reporting: the node being evaluated and the active pcl calls
+	node      ast.ASTNode
+	callStack []runtime.StackFrame
+
+	// where print / input talk to
+	stdout io.Writer
+	stdin  *bufio.Reader
 }
 
 func NewInterpreter() *Interpreter {
@@ -15,10 +32,22 @@ func NewInterpreter() *Interpreter {
 	globalScope.SetVariable("false", &runtime.BooleanValue{Value: false})
 	globalScope.SetVariable("true", &runtime.BooleanValue{Value: true})
 
-	return &Interpreter{
+	interpreter := &Interpreter{
 		currentScope: globalScope,
 		globalScope:  globalScope,
+		stdout:       os.Stdout,
+		stdin:        bufio.NewReader(os.Stdin),
 	}
+
+	interpreter.registerBuiltins()
+
+	return interpreter
+}
+
+// Stdin is the reader input() uses; the repl reads its lines through it too,
+// two buffered readers on one stdin would steal input from each other
+func (interpreter *Interpreter) Stdin() *bufio.Reader {
+	return interpreter.stdin
 }
 
 // --- scope management ---
@@ -0,0 +1,314 @@
+package interpreter
+
+import (
+	"pcl/src/frontend/ast"
+	"pcl/src/runtime"
+	"strings"
+)
+
+func (interpreter *Interpreter) evalMember(node *ast.MemberNode) runtime.RuntimeValue {
+	object := interpreter.evaluate(node.Object)
+	if skipChain(object, node.Optional) {
+		return shortCircuit
+	}
+	return interpreter.getProperty(object, node.Property)
+}
+
+func (interpreter *Interpreter) evalMethodCall(node *ast.MethodCallNode) runtime.RuntimeValue {
+	object := interpreter.evaluate(node.Object)
+	if skipChain(object, node.Optional) {
+		return shortCircuit // arguments are not evaluated either
+	}
+	method := interpreter.getProperty(object, node.Method)
+
+	return interpreter.callFunction(method, interpreter.evalArguments(node.Arguments))
+}
+
+// shortCircuit is returned by a ?. step that met nil, and passed along by
+// every later step of the chain up to its OptionalChainNode, which turns it
+// into a plain nil. It has a type of its own because pointers to NilValue,
+// an empty struct, may all compare equal.
+type shortCircuitValue struct{}
+
+func (shortCircuitValue) Type() runtime.ValueType { return runtime.NilValueType }
+func (shortCircuitValue) String() string          { return "ShortCircuit" }
+
+var shortCircuit runtime.RuntimeValue = shortCircuitValue{}
+
+// skipChain reports whether a step on object should cut the chain short
+func skipChain(object runtime.RuntimeValue, optional bool) bool {
+	if object == shortCircuit {
+		return true
+	}
+	_, isNil := object.(*runtime.NilValue)
+	return isNil && optional
+}
+
+func (interpreter *Interpreter) evalOptionalChain(node *ast.OptionalChainNode) runtime.RuntimeValue {
+	value := interpreter.evaluate(node.Chain)
+	if value == shortCircuit {
+		return &runtime.NilValue{}
+	}
+	return value
+}
+
+// obj.field = v and its compound forms
+func (interpreter *Interpreter) assignMember(node *ast.MemberNode, operator string, value runtime.RuntimeValue) runtime.RuntimeValue {
+	object := interpreter.evaluate(node.Object)
+
+	setter, ok := object.(runtime.PropertySetter)
+	if !ok {
+		panic(runtime.Errorf("cannot set property '%s' on %s", node.Property, runtime.TypeName(object)))
+	}
+
+	if operator != "=" {
+		current := interpreter.getProperty(object, node.Property)
+		value = interpreter.evalArithmetic(current, value, strings.TrimSuffix(operator, "="))
+	}
+
+	if !setter.SetProperty(node.Property, value) {
+		panic(runtime.Errorf("cannot set property '%s' on %s", node.Property, runtime.TypeName(object)))
+	}
+
+	return value
+}
+
+// getProperty is the lookup behind the dot operator: the value's own fields
+// first (runtime.PropertyHolder), then the built-in methods of its type
+func (interpreter *Interpreter) getProperty(object runtime.RuntimeValue, name string) runtime.RuntimeValue {
+	if holder, ok := object.(runtime.PropertyHolder); ok {
+		if value, found := holder.GetProperty(name); found {
+			return value
+		}
+	}
+
+	if method := interpreter.builtinMethod(object, name); method != nil {
+		return method
+	}
+
+	// maps behave like objects, unknown fields read as nil just like m["missing"]
+	if _, ok := object.(*runtime.MapValue); ok {
+		return &runtime.NilValue{}
+	}
+
+	if enum, ok := object.(*runtime.EnumValue); ok {
+		panic(unknownVariant(enum, name))
+	}
+
+	panic(runtime.Errorf("%s has no property '%s'", runtime.TypeName(object), name))
+}
+
+func (interpreter *Interpreter) builtinMethod(object runtime.RuntimeValue, name string) *runtime.NativeFunctionValue {
+	var fn func(args []runtime.RuntimeValue) runtime.RuntimeValue
+
+	switch obj := object.(type) {
+	case *runtime.StringValue:
+		fn = stringMethod(obj, name)
+	case *runtime.ArrayValue:
+		fn = interpreter.arrayMethod(obj, name)
+	case *runtime.MapValue:
+		fn = mapMethod(obj, name)
+	}
+
+	if fn == nil {
+		return nil
+	}
+
+	// methods check their own arity, since some (push) are variadic
+	return &runtime.NativeFunctionValue{Name: name, Arity: -1, Fn: fn}
+}
+
+// ---------- string methods ----------
+
+func stringMethod(str *runtime.StringValue, name string) func(args []runtime.RuntimeValue) runtime.RuntimeValue {
+	switch name {
+	case "len":
+		return func(args []runtime.RuntimeValue) runtime.RuntimeValue {
+			expectArgs(name, args, 0)
+			return &runtime.IntValue{Value: len([]rune(str.Value))}
+		}
+	case "upper":
+		return func(args []runtime.RuntimeValue) runtime.RuntimeValue {
+			expectArgs(name, args, 0)
+			return &runtime.StringValue{Value: strings.ToUpper(str.Value)}
+		}
+	case "lower":
+		return func(args []runtime.RuntimeValue) runtime.RuntimeValue {
+			expectArgs(name, args, 0)
+			return &runtime.StringValue{Value: strings.ToLower(str.Value)}
+		}
+	case "trim":
+		return func(args []runtime.RuntimeValue) runtime.RuntimeValue {
+			expectArgs(name, args, 0)
+			return &runtime.StringValue{Value: strings.TrimSpace(str.Value)}
+		}
+	case "split":
+		return func(args []runtime.RuntimeValue) runtime.RuntimeValue {
+			expectArgs(name, args, 1)
+			parts := strings.Split(str.Value, stringArg(name, args, 0))
+			elements := make([]runtime.RuntimeValue, len(parts))
+			for i, part := range parts {
+				elements[i] = &runtime.StringValue{Value: part}
+			}
+			return &runtime.ArrayValue{Elements: elements}
+		}
+	case "contains":
+		return func(args []runtime.RuntimeValue) runtime.RuntimeValue {
+			expectArgs(name, args, 1)
+			return &runtime.BooleanValue{Value: strings.Contains(str.Value, stringArg(name, args, 0))}
+		}
+	case "startsWith":
+		return func(args []runtime.RuntimeValue) runtime.RuntimeValue {
+			expectArgs(name, args, 1)
+			return &runtime.BooleanValue{Value: strings.HasPrefix(str.Value, stringArg(name, args, 0))}
+		}
+	case "endsWith":
+		return func(args []runtime.RuntimeValue) runtime.RuntimeValue {
+			expectArgs(name, args, 1)
+			return &runtime.BooleanValue{Value: strings.HasSuffix(str.Value, stringArg(name, args, 0))}
+		}
+	case "replace":
+		return func(args []runtime.RuntimeValue) runtime.RuntimeValue {
+			expectArgs(name, args, 2)
+			return &runtime.StringValue{Value: strings.ReplaceAll(str.Value, stringArg(name, args, 0), stringArg(name, args, 1))}
+		}
+	case "indexOf":
+		return func(args []runtime.RuntimeValue) runtime.RuntimeValue {
+			expectArgs(name, args, 1)
+			i := strings.Index(str.Value, stringArg(name, args, 0))
+			if i >= 0 {
+				i = len([]rune(str.Value[:i])) // byte offset -> character offset
+			}
+			return &runtime.IntValue{Value: i}
+		}
+	}
+
+	return nil
+}
+
+// ---------- array methods ----------
+
+func (interpreter *Interpreter) arrayMethod(array *runtime.ArrayValue, name string) func(args []runtime.RuntimeValue) runtime.RuntimeValue {
+	switch name {
+	case "len":
+		return func(args []runtime.RuntimeValue) runtime.RuntimeValue {
+			expectArgs(name, args, 0)
+			return &runtime.IntValue{Value: len(array.Elements)}
+		}
+	case "push":
+		return func(args []runtime.RuntimeValue) runtime.RuntimeValue {
+			array.Elements = append(array.Elements, args...)
+			return &runtime.IntValue{Value: len(array.Elements)}
+		}
+	case "pop":
+		return func(args []runtime.RuntimeValue) runtime.RuntimeValue {
+			expectArgs(name, args, 0)
+			if len(array.Elements) == 0 {
+				panic(runtime.Errorf("pop from empty array"))
+			}
+			last := array.Elements[len(array.Elements)-1]
+			array.Elements = array.Elements[:len(array.Elements)-1]
+			return last
+		}
+	case "contains":
+		return func(args []runtime.RuntimeValue) runtime.RuntimeValue {
+			expectArgs(name, args, 1)
+			return interpreter.evalMembership(args[0], array)
+		}
+	case "indexOf":
+		return func(args []runtime.RuntimeValue) runtime.RuntimeValue {
+			expectArgs(name, args, 1)
+			for i, element := range array.Elements {
+				if runtimeEqual(element, args[0]) {
+					return &runtime.IntValue{Value: i}
+				}
+			}
+			return &runtime.IntValue{Value: -1}
+		}
+	case "join":
+		return func(args []runtime.RuntimeValue) runtime.RuntimeValue {
+			expectArgs(name, args, 1)
+			parts := make([]string, len(array.Elements))
+			for i, element := range array.Elements {
+				str, ok := element.(*runtime.StringValue)
+				if !ok {
+					panic(runtime.Errorf("join expects an array of strings"))
+				}
+				parts[i] = str.Value
+			}
+			return &runtime.StringValue{Value: strings.Join(parts, stringArg(name, args, 0))}
+		}
+	case "map":
+		return func(args []runtime.RuntimeValue) runtime.RuntimeValue {
+			expectArgs(name, args, 1)
+			mapped := make([]runtime.RuntimeValue, len(array.Elements))
+			for i, element := range array.Elements {
+				mapped[i] = interpreter.callFunction(args[0], []runtime.RuntimeValue{element})
+			}
+			return &runtime.ArrayValue{Elements: mapped}
+		}
+	case "filter":
+		return func(args []runtime.RuntimeValue) runtime.RuntimeValue {
+			expectArgs(name, args, 1)
+			kept := []runtime.RuntimeValue{}
+			for _, element := range array.Elements {
+				if runtime.Truthy(interpreter.callFunction(args[0], []runtime.RuntimeValue{element})) {
+					kept = append(kept, element)
+				}
+			}
+			return &runtime.ArrayValue{Elements: kept}
+		}
+	}
+
+	return nil
+}
+
+// ---------- map methods ----------
+
+func mapMethod(m *runtime.MapValue, name string) func(args []runtime.RuntimeValue) runtime.RuntimeValue {
+	switch name {
+	case "len":
+		return func(args []runtime.RuntimeValue) runtime.RuntimeValue {
+			expectArgs(name, args, 0)
+			return &runtime.IntValue{Value: m.Len()}
+		}
+	case "keys":
+		return func(args []runtime.RuntimeValue) runtime.RuntimeValue {
+			expectArgs(name, args, 0)
+			return &runtime.ArrayValue{Elements: m.Keys()}
+		}
+	case "values":
+		return func(args []runtime.RuntimeValue) runtime.RuntimeValue {
+			expectArgs(name, args, 0)
+			return &runtime.ArrayValue{Elements: m.Values()}
+		}
+	case "has":
+		return func(args []runtime.RuntimeValue) runtime.RuntimeValue {
+			expectArgs(name, args, 1)
+			return &runtime.BooleanValue{Value: m.Has(args[0])}
+		}
+	case "remove":
+		return func(args []runtime.RuntimeValue) runtime.RuntimeValue {
+			expectArgs(name, args, 1)
+			return &runtime.BooleanValue{Value: m.Delete(args[0])}
+		}
+	}
+
+	return nil
+}
+
+// ---------- argument helpers ----------
+
+func expectArgs(name string, args []runtime.RuntimeValue, count int) {
+	if len(args) != count {
+		panic(runtime.Errorf("%s expects %d arguments, got %d", name, count, len(args)))
+	}
+}
+
+func stringArg(name string, args []runtime.RuntimeValue, i int) string {
+	str, ok := args[i].(*runtime.StringValue)
+	if !ok {
+		panic(runtime.Errorf("%s expects argument %d to be a string", name, i+1))
+	}
+	return str.Value
+}
@@ -0,0 +1,173 @@
+package interpreter
+
+import (
+	"pcl/src/frontend/ast"
+	"pcl/src/runtime"
+)
+
+// evalMatch tries the arms in order. Each arm gets a fresh scope for the names
+// its pattern binds, so a failed arm leaves nothing behind.
+func (interpreter *Interpreter) evalMatch(node *ast.MatchNode) runtime.RuntimeValue {
+	subject := interpreter.evaluate(node.Subject)
+	prevScope := interpreter.currentScope
+
+	for _, arm := range node.Arms {
+		interpreter.currentScope = runtime.NewScope(prevScope)
+
+		if interpreter.matchPattern(arm.Pattern, subject) != nil {
+			continue
+		}
+		if arm.Guard != nil && !runtime.Truthy(interpreter.evaluate(arm.Guard)) {
+			continue
+		}
+
+		result := interpreter.evaluate(arm.Body)
+		interpreter.currentScope = prevScope
+		return result
+	}
+
+	interpreter.currentScope = prevScope
+	panic(runtime.Errorf("no match arm matched %s", runtime.Inspect(subject)))
+}
+
+// matchPattern binds the pattern's names in the current scope and returns nil
+// when value matches, otherwise an error saying why it doesn't. Mistakes in
+// the pattern itself, like an unknown enum, panic instead.
+func (interpreter *Interpreter) matchPattern(pattern ast.ASTNode, value runtime.RuntimeValue) *runtime.RuntimeError {
+	switch pattern := pattern.(type) {
+	case *ast.WildcardPatternNode:
+		return nil
+
+	case *ast.BindingPatternNode:
+		interpreter.currentScope.SetVariable(pattern.Name, value)
+		return nil
+
+	case *ast.LiteralPatternNode:
+		expected := interpreter.evaluate(pattern.Value)
+		if !runtimeEqual(value, expected) {
+			return runtime.Errorf("expected %s, got %s", runtime.Inspect(expected), runtime.Inspect(value))
+		}
+		return nil
+
+	case *ast.RangePatternNode:
+		return interpreter.matchRange(pattern, value)
+
+	case *ast.ArrayPatternNode:
+		return interpreter.matchArray(pattern, value)
+
+	case *ast.MapPatternNode:
+		return interpreter.matchMap(pattern, value)
+
+	case *ast.VariantPatternNode:
+		return interpreter.matchVariant(pattern, value)
+
+	default:
+		panic(runtime.Errorf("unsupported pattern type: %d", pattern.Type()))
+	}
+}
+
+func (interpreter *Interpreter) matchRange(pattern *ast.RangePatternNode, value runtime.RuntimeValue) *runtime.RuntimeError {
+	low, high := interpreter.evaluate(pattern.Low), interpreter.evaluate(pattern.High)
+	if !isNumber(low) || !isNumber(high) {
+		panic(runtime.Errorf("range pattern bounds must be numbers, got %s and %s", runtime.TypeName(low), runtime.TypeName(high)))
+	}
+
+	operator := ".."
+	if pattern.Inclusive {
+		operator = "..="
+	}
+	mismatch := runtime.Errorf("expected a number in %s%s%s, got %s",
+		runtime.Inspect(low), operator, runtime.Inspect(high), runtime.Inspect(value))
+
+	if !isNumber(value) {
+		return mismatch
+	}
+
+	fromLow, ok1 := compareNumbers(value, low)
+	toHigh, ok2 := compareNumbers(value, high)
+	if !ok1 || !ok2 || fromLow < 0 || toHigh > 0 || (toHigh == 0 && !pattern.Inclusive) {
+		return mismatch
+	}
+	return nil
+}
+
+func (interpreter *Interpreter) matchArray(pattern *ast.ArrayPatternNode, value runtime.RuntimeValue) *runtime.RuntimeError {
+	array, ok := value.(*runtime.ArrayValue)
+	if !ok {
+		return runtime.Errorf("expected an array, got %s", runtime.TypeName(value))
+	}
+
+	count := len(pattern.Elements)
+	if pattern.Rest == nil && len(array.Elements) != count {
+		return runtime.Errorf("expected an array of %d elements, got %d", count, len(array.Elements))
+	}
+	if pattern.Rest != nil && len(array.Elements) < count {
+		return runtime.Errorf("expected an array of at least %d elements, got %d", count, len(array.Elements))
+	}
+
+	for i, element := range pattern.Elements {
+		if err := interpreter.matchPattern(element, array.Elements[i]); err != nil {
+			return err
+		}
+	}
+
+	if pattern.Rest != nil {
+		rest := append([]runtime.RuntimeValue{}, array.Elements[count:]...)
+		return interpreter.matchPattern(pattern.Rest, &runtime.ArrayValue{Elements: rest})
+	}
+	return nil
+}
+
+// maps are matched by string key, structs, instances and enum values by field
+func (interpreter *Interpreter) matchMap(pattern *ast.MapPatternNode, value runtime.RuntimeValue) *runtime.RuntimeError {
+	var lookup func(key string) (runtime.RuntimeValue, bool)
+
+	switch object := value.(type) {
+	case *runtime.MapValue:
+		lookup = func(key string) (runtime.RuntimeValue, bool) {
+			return object.Get(&runtime.StringValue{Value: key})
+		}
+	case runtime.PropertyHolder:
+		lookup = object.GetProperty
+	default:
+		return runtime.Errorf("expected a map or object, got %s", runtime.TypeName(value))
+	}
+
+	for i, key := range pattern.Keys {
+		field, found := lookup(key)
+		if !found {
+			return runtime.Errorf("%s has no key '%s'", runtime.TypeName(value), key)
+		}
+		if err := interpreter.matchPattern(pattern.Values[i], field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (interpreter *Interpreter) matchVariant(pattern *ast.VariantPatternNode, value runtime.RuntimeValue) *runtime.RuntimeError {
+	enum, ok := interpreter.evaluate(&ast.IdentifierNode{Pos: pattern.Pos, Name: pattern.Enum}).(*runtime.EnumValue)
+	if !ok {
+		panic(runtime.Errorf("%s is not an enum", pattern.Enum))
+	}
+
+	variant, ok := enum.Variant(pattern.Variant)
+	if !ok {
+		panic(unknownVariant(enum, pattern.Variant))
+	}
+	if pattern.Fields != nil && len(pattern.Fields) != len(variant.Fields) {
+		panic(runtime.Errorf("%s has %d fields, the pattern lists %d", variant.FullName(), len(variant.Fields), len(pattern.Fields)))
+	}
+
+	instance, ok := value.(*runtime.EnumInstanceValue)
+	if !ok || instance.Variant != variant {
+		return runtime.Errorf("expected %s, got %s", variant.FullName(), runtime.Inspect(value))
+	}
+
+	for i, field := range pattern.Fields {
+		if err := interpreter.matchPattern(field, instance.Payload[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
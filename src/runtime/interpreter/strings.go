@@ -0,0 +1,16 @@
+package interpreter
+
+import (
+	"pcl/src/frontend/ast"
+	"pcl/src/runtime"
+	"strings"
+)
+
+// each part is shown the way print shows it, so "${[1, "a"]}" is [1, "a"]
+func (interpreter *Interpreter) evalInterpolation(node *ast.InterpolationNode) runtime.RuntimeValue {
+	var sb strings.Builder
+	for _, part := range node.Parts {
+		sb.WriteString(runtime.Display(interpreter.evaluate(part)))
+	}
+	return &runtime.StringValue{Value: sb.String()}
+}
@@ -1,28 +1,45 @@
 package interpreter
 
 import (
+	"math"
+	"math/big"
 	"pcl/src/frontend/ast"
 	"pcl/src/runtime"
 )
 
 func (interpreter *Interpreter) evalUnary(node *ast.UnaryOpNode) runtime.RuntimeValue {
-	operand := interpreter.Evaluate(node.Operand)
+	operand := interpreter.evaluate(node.Operand)
 	switch node.Operator {
 	case "-":
 		switch num := operand.(type) {
 		case *runtime.IntValue:
+			if num.Value == math.MinInt {
+				return runtime.NewInt(new(big.Int).Neg(big.NewInt(int64(num.Value))))
+			}
 			return &runtime.IntValue{Value: -num.Value}
+		case *runtime.BigIntValue:
+			return runtime.NewInt(new(big.Int).Neg(num.Value))
 		case *runtime.FloatValue:
 			return &runtime.FloatValue{Value: -num.Value}
 		default:
-			panic("cannot negate non-number")
+			panic(runtime.Errorf("cannot negate non-number"))
 		}
+	case "+":
+		if isNumber(operand) {
+			return operand
+		}
+		panic(runtime.Errorf("unary + expects a number, got %s", runtime.TypeName(operand)))
+	case "!":
+		return &runtime.BooleanValue{Value: !runtime.Truthy(operand)}
 	case "~":
-		if num, ok := operand.(*runtime.IntValue); ok {
+		switch num := operand.(type) {
+		case *runtime.IntValue:
 			return &runtime.IntValue{Value: ^num.Value}
+		case *runtime.BigIntValue:
+			return runtime.NewInt(new(big.Int).Not(num.Value))
 		}
-		panic("bitwise not only works on ints")
+		panic(runtime.Errorf("bitwise not only works on ints"))
 	default:
-		panic("unknown unary operator: " + node.Operator)
+		panic(runtime.Errorf("unknown unary operator: %s", node.Operator))
 	}
 }
@@ -1,32 +1,126 @@
 package interpreter
 
 import (
+	"fmt"
 	"pcl/src/frontend/ast"
+	"pcl/src/frontend/lexer"
+	"pcl/src/frontend/source"
 	"pcl/src/runtime"
+	"strings"
 )
 
 func (interpreter *Interpreter) evalVarDecl(node *ast.VarDeclNode) runtime.RuntimeValue {
 	var val runtime.RuntimeValue = &runtime.NilValue{}
 
 	if node.Value != nil {
-		val = interpreter.Evaluate(node.Value)
+		val = interpreter.evaluate(node.Value)
+	}
+
+	if node.Pattern != nil {
+		// match into a scratch scope so a failed destructure binds nothing
+		prevScope := interpreter.currentScope
+		interpreter.currentScope = runtime.NewScope(prevScope)
+		err := interpreter.matchPattern(node.Pattern, val)
+		bindings := interpreter.currentScope.Locals()
+		interpreter.currentScope = prevScope
+
+		if err != nil {
+			panic(runtime.Errorf("cannot destructure: %s", err.Message))
+		}
+		for name, value := range bindings {
+			interpreter.currentScope.SetVariable(name, value)
+		}
+		return val
 	}
 
 	return interpreter.currentScope.SetVariable(node.Name, val)
 }
 
 func (interpreter *Interpreter) evalAssignment(node *ast.AssignmentNode) runtime.RuntimeValue {
-	if !interpreter.currentScope.HasVariable(node.Name) {
-		return interpreter.currentScope.SetVariable(node.Name, interpreter.Evaluate(node.Value))
+	switch target := node.Target.(type) {
+	case *ast.IdentifierNode:
+		if !interpreter.currentScope.HasVariable(target.Name) {
+			panic(interpreter.undefinedVariable("cannot assign to undeclared variable: %s", target.Name))
+		}
+
+		value := interpreter.evaluate(node.Value)
+
+		// compound forms: x += v is x = x + v
+		if node.Operator != "=" {
+			current := interpreter.currentScope.GetVariable(target.Name)
+			value = interpreter.evalArithmetic(current, value, strings.TrimSuffix(node.Operator, "="))
+		}
+
+		return interpreter.currentScope.AssignVariable(target.Name, value)
+
+	case *ast.IndexNode:
+		return interpreter.assignIndex(target, node.Operator, interpreter.evaluate(node.Value))
+
+	case *ast.MemberNode:
+		return interpreter.assignMember(target, node.Operator, interpreter.evaluate(node.Value))
+
+	default:
+		panic(runtime.Errorf("invalid assignment target"))
+	}
+}
+
+func (interpreter *Interpreter) evalParallelAssignment(node *ast.ParallelAssignmentNode) runtime.RuntimeValue {
+	values := interpreter.evalArguments(node.Values)
+
+	if len(node.Values) == 1 && len(node.Targets) > 1 {
+		array, ok := values[0].(*runtime.ArrayValue)
+		if !ok {
+			panic(runtime.Errorf("cannot unpack %s into %d targets", runtime.TypeName(values[0]), len(node.Targets)))
+		}
+		if len(array.Elements) != len(node.Targets) {
+			panic(runtime.Errorf("cannot unpack an array of %d elements into %d targets", len(array.Elements), len(node.Targets)))
+		}
+		values = array.Elements
 	}
 
-	panic("variable not found: " + node.Name)
+	for i, target := range node.Targets {
+		interpreter.assignTo(target, values[i])
+	}
+
+	return &runtime.NilValue{}
+}
+
+// plain '=' to an already evaluated value, for targets of parallel assignment
+func (interpreter *Interpreter) assignTo(target ast.ASTNode, value runtime.RuntimeValue) runtime.RuntimeValue {
+	switch target := target.(type) {
+	case *ast.IdentifierNode:
+		if !interpreter.currentScope.HasVariable(target.Name) {
+			panic(interpreter.undefinedVariable("cannot assign to undeclared variable: %s", target.Name))
+		}
+		return interpreter.currentScope.AssignVariable(target.Name, value)
+	case *ast.IndexNode:
+		return interpreter.assignIndex(target, "=", value)
+	case *ast.MemberNode:
+		return interpreter.assignMember(target, "=", value)
+	default:
+		panic(runtime.Errorf("invalid assignment target"))
+	}
 }
 
 func (interpreter *Interpreter) evalIdentifier(node *ast.IdentifierNode) runtime.RuntimeValue {
 	if interpreter.currentScope.HasVariable(node.Name) {
 		return interpreter.currentScope.GetVariable(node.Name)
-	} 
-	
-	panic("variable not found: " + node.Name)
+	}
+
+	panic(interpreter.undefinedVariable("variable not found: %s", node.Name))
+}
+
+// undefinedVariable suggests the closest name in scope, or failing that the
+// closest keyword, since "fucn f() {}" parses as a call to fucn
+func (interpreter *Interpreter) undefinedVariable(format string, name string) *runtime.RuntimeError {
+	err := runtime.Errorf(format, name)
+	err.Label = "not found in this scope"
+
+	if closest, ok := source.Closest(name, interpreter.currentScope.Names()); ok {
+		err.Hint = fmt.Sprintf("did you mean '%s'?", closest)
+	} else if keyword, ok := source.Closest(name, lexer.Keywords()); ok {
+		err.Hint = fmt.Sprintf("did you mean the keyword '%s'?", keyword)
+	}
+
+	return err
 }
@@ -0,0 +1,121 @@
+package runtime
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// map that remembers insertion order, so printing and for-in are deterministic
+type MapValue struct {
+	keys    []RuntimeValue
+	values  []RuntimeValue
+	indexes map[string]int // hashKey(key) -> position in keys / values
+}
+
+func NewMapValue() *MapValue {
+	return &MapValue{indexes: make(map[string]int)}
+}
+
+func (m *MapValue) Type() ValueType { return MapValueType }
+func (m *MapValue) String() string {
+	entries := make([]string, len(m.keys))
+	for i, key := range m.keys {
+		entries[i] = key.String() + ": " + m.values[i].String()
+	}
+	return fmt.Sprintf("MapValue { %s }", strings.Join(entries, ", "))
+}
+
+// for-in walks the keys in insertion order
+func (m *MapValue) Iterate() []RuntimeValue {
+	return m.Keys()
+}
+
+func (m *MapValue) Len() int { return len(m.keys) }
+
+func (m *MapValue) Keys() []RuntimeValue {
+	return append([]RuntimeValue(nil), m.keys...)
+}
+
+func (m *MapValue) Values() []RuntimeValue {
+	return append([]RuntimeValue(nil), m.values...)
+}
+
+func (m *MapValue) Get(key RuntimeValue) (RuntimeValue, bool) {
+	if i, ok := m.indexes[hashKey(key)]; ok {
+		return m.values[i], true
+	}
+	return nil, false
+}
+
+func (m *MapValue) Has(key RuntimeValue) bool {
+	_, ok := m.indexes[hashKey(key)]
+	return ok
+}
+
+// Set overwrites in place, so an existing key keeps its original position
+func (m *MapValue) Set(key, value RuntimeValue) {
+	hash := hashKey(key)
+	if i, ok := m.indexes[hash]; ok {
+		m.values[i] = value
+		return
+	}
+
+	m.indexes[hash] = len(m.keys)
+	m.keys = append(m.keys, key)
+	m.values = append(m.values, value)
+}
+
+func (m *MapValue) Delete(key RuntimeValue) bool {
+	hash := hashKey(key)
+	i, ok := m.indexes[hash]
+	if !ok {
+		return false
+	}
+
+	m.keys = append(m.keys[:i], m.keys[i+1:]...)
+	m.values = append(m.values[:i], m.values[i+1:]...)
+	delete(m.indexes, hash)
+
+	// everything after the removed entry shifted down by one
+	for j := i; j < len(m.keys); j++ {
+		m.indexes[hashKey(m.keys[j])] = j
+	}
+
+	return true
+}
+
+// m.name reads the "name" entry
+func (m *MapValue) GetProperty(name string) (RuntimeValue, bool) {
+	return m.Get(&StringValue{Value: name})
+}
+
+func (m *MapValue) SetProperty(name string, value RuntimeValue) bool {
+	m.Set(&StringValue{Value: name}, value)
+	return true
+}
+
+// hashKey maps equal keys to the same string; 1 and 1.0 are the same key
+func hashKey(key RuntimeValue) string {
+	switch k := key.(type) {
+	case *StringValue:
+		return "s:" + k.Value
+	case *IntValue:
+		return fmt.Sprintf("i:%d", k.Value)
+	case *BigIntValue:
+		return "i:" + k.Value.String()
+	case *FloatValue:
+		if k.Value == math.Trunc(k.Value) && !math.IsInf(k.Value, 0) {
+			integer, _ := big.NewFloat(k.Value).Int(nil)
+			return "i:" + integer.String()
+		}
+		return fmt.Sprintf("f:%v", k.Value)
+	case *BooleanValue:
+		return fmt.Sprintf("b:%t", k.Value)
+	case *NilValue:
+		return "nil"
+	default:
+		panic(Errorf("unhashable map key type"))
+	}
+}
@@ -21,7 +21,7 @@ func (scope *Scope) GetVariable(name string) RuntimeValue {
 		return scope.Parent.GetVariable(name)
 	}
 
-	panic("variable not found: " + name)
+	panic(Errorf("variable not found: %s", name))
 }
 
 func (scope *Scope) SetVariable(name string, value RuntimeValue) RuntimeValue {
@@ -29,6 +29,20 @@ func (scope *Scope) SetVariable(name string, value RuntimeValue) RuntimeValue {
 	return value
 }
 
+// AssignVariable updates an existing variable in whichever scope owns it
+func (scope *Scope) AssignVariable(name string, value RuntimeValue) RuntimeValue {
+	if _, ok := scope.variables[name]; ok {
+		scope.variables[name] = value
+		return value
+	}
+
+	if scope.Parent != nil {
+		return scope.Parent.AssignVariable(name, value)
+	}
+
+	panic(Errorf("cannot assign to undeclared variable: %s", name))
+}
+
 func (scope *Scope) HasVariable(name string) bool {
 	if _, ok := scope.variables[name]; ok {
 		return true
@@ -41,6 +55,32 @@ func (scope *Scope) HasVariable(name string) bool {
 	return false
 }
 
+// Locals copies the variables declared directly in this scope, not its parents
+func (scope *Scope) Locals() map[string]RuntimeValue {
+	locals := make(map[string]RuntimeValue, len(scope.variables))
+	for name, value := range scope.variables {
+		locals[name] = value
+	}
+	return locals
+}
+
+// Names lists every variable visible from this scope, inner scopes first
+func (scope *Scope) Names() []string {
+	var names []string
+	seen := make(map[string]bool)
+
+	for s := scope; s != nil; s = s.Parent {
+		for name := range s.variables {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
 func (scope *Scope) String() string {
 	result := "Scope {\n"
 	for name, value := range scope.variables {
@@ -48,4 +88,4 @@ func (scope *Scope) String() string {
 	}
 	result += "}"
 	return result
-}
\ No newline at end of file
+}
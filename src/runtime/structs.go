@@ -0,0 +1,56 @@
+package runtime
+
+import "fmt"
+
+// StructValue is what `struct Point { x, y }` declares. Calling it with one
+// argument per field builds an instance.
+type StructValue struct {
+	Name   string
+	Fields []string
+}
+
+func (s *StructValue) Type() ValueType { return StructValueType }
+func (s *StructValue) String() string {
+	return fmt.Sprintf("StructValue { Name: %s, Fields: %v }", s.Name, s.Fields)
+}
+
+func (s *StructValue) fieldIndex(name string) int {
+	for i, field := range s.Fields {
+		if field == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *StructValue) New(values []RuntimeValue) *StructInstanceValue {
+	return &StructInstanceValue{Struct: s, Values: values}
+}
+
+// an instance, its values line up with Struct.Fields
+type StructInstanceValue struct {
+	Struct *StructValue
+	Values []RuntimeValue
+}
+
+func (s *StructInstanceValue) Type() ValueType  { return StructInstanceValueType }
+func (s *StructInstanceValue) TypeName() string { return s.Struct.Name }
+func (s *StructInstanceValue) String() string {
+	return fmt.Sprintf("StructInstanceValue { Struct: %s, Values: %v }", s.Struct.Name, s.Values)
+}
+
+func (s *StructInstanceValue) GetProperty(name string) (RuntimeValue, bool) {
+	if i := s.Struct.fieldIndex(name); i >= 0 {
+		return s.Values[i], true
+	}
+	return nil, false
+}
+
+// only declared fields can be set, p.z = 1 on a Point is an error
+func (s *StructInstanceValue) SetProperty(name string, value RuntimeValue) bool {
+	if i := s.Struct.fieldIndex(name); i >= 0 {
+		s.Values[i] = value
+		return true
+	}
+	return false
+}
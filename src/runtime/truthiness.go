@@ -0,0 +1,27 @@
+package runtime
+
+// Truthy is the one rule for what counts as true wherever a condition is
+// expected (if, while, for, ?:, !, && and ||): nil, false, 0, 0.0, "" and
+// empty arrays and maps are false, every other value is true
+func Truthy(value RuntimeValue) bool {
+	switch v := value.(type) {
+	case *NilValue:
+		return false
+	case *BooleanValue:
+		return v.Value
+	case *IntValue:
+		return v.Value != 0
+	case *BigIntValue:
+		return v.Value.Sign() != 0 // never actually zero, NewInt shrinks that
+	case *FloatValue:
+		return v.Value != 0
+	case *StringValue:
+		return v.Value != ""
+	case *ArrayValue:
+		return len(v.Elements) > 0
+	case *MapValue:
+		return v.Len() > 0
+	default:
+		return true
+	}
+}
@@ -2,6 +2,7 @@ package runtime
 
 import (
 	"fmt"
+	"math/big"
 	"pcl/src/frontend/ast"
 )
 
@@ -10,20 +11,100 @@ type ValueType int
 
 const (
 	IntValueType ValueType = iota
+	BigIntValueType
 	FloatValueType
 	StringValueType
 	BooleanValueType
 	NilValueType
 	FunctionValueType
+	NativeFunctionValueType
+	ArrayValueType
+	MapValueType
+	ErrorValueType
+	StructValueType
+	StructInstanceValueType
+	ClassValueType
+	InstanceValueType
+	EnumValueType
+	EnumVariantValueType
+	EnumInstanceValueType
 	ReturnValueType
+	BreakValueType
+	ContinueValueType
 )
 
+// user facing names, as returned by the type() builtin
+func (t ValueType) String() string {
+	names := [...]string{
+		"int",
+		"int", // big ints are an implementation detail
+		"float",
+		"string",
+		"bool",
+		"nil",
+		"function",
+		"function",
+		"array",
+		"map",
+		"error",
+		"struct",
+		"struct", // type() reports the struct's own name, see TypeName
+		"class",
+		"instance", // same, reported by class name
+		"enum",
+		"variant",
+		"enum", // same, reported by enum name
+		"return",
+		"break",
+		"continue",
+	}
+
+	if int(t) < 0 || int(t) >= len(names) {
+		return "unknown"
+	}
+
+	return names[t]
+}
+
 // interface
 type RuntimeValue interface {
 	Type() ValueType
 	String() string
 }
 
+// implemented by values whose type has a user-given name, e.g. struct instances
+type TypeNamer interface {
+	RuntimeValue
+	TypeName() string
+}
+
+// TypeName is what the type() builtin and error messages call a value's type
+func TypeName(value RuntimeValue) string {
+	if named, ok := value.(TypeNamer); ok {
+		return named.TypeName()
+	}
+	return value.Type().String()
+}
+
+// implemented by values that carry their own fields for the dot operator;
+// built-in methods are looked up by the interpreter when this comes up empty
+type PropertyHolder interface {
+	RuntimeValue
+	GetProperty(name string) (RuntimeValue, bool)
+}
+
+// implemented by values that accept 'obj.field = value'
+type PropertySetter interface {
+	RuntimeValue
+	SetProperty(name string, value RuntimeValue) bool
+}
+
+// implemented by values that can be walked with 'for x in value'
+type Iterable interface {
+	RuntimeValue
+	Iterate() []RuntimeValue
+}
+
 // int
 type IntValue struct {
 	Value int
@@ -34,6 +115,38 @@ func (v *IntValue) String() string {
 	return fmt.Sprintf("IntValue { Value: %d }", v.Value)
 }
 
+// int that no longer fits in a Go int, see NewInt
+type BigIntValue struct {
+	Value *big.Int
+}
+
+func (v *BigIntValue) Type() ValueType { return BigIntValueType }
+func (v *BigIntValue) String() string {
+	return fmt.Sprintf("BigIntValue { Value: %s }", v.Value)
+}
+
+// NewInt picks the representation for an integer result: an IntValue whenever
+// it fits, so big ints only exist while a value actually needs them
+func NewInt(n *big.Int) RuntimeValue {
+	if n.IsInt64() {
+		if v := n.Int64(); int64(int(v)) == v {
+			return &IntValue{Value: int(v)}
+		}
+	}
+	return &BigIntValue{Value: n}
+}
+
+// BigInt widens either int representation to a *big.Int
+func BigInt(value RuntimeValue) (*big.Int, bool) {
+	switch v := value.(type) {
+	case *IntValue:
+		return big.NewInt(int64(v.Value)), true
+	case *BigIntValue:
+		return v.Value, true
+	}
+	return nil, false
+}
+
 // float
 type FloatValue struct {
 	Value float64
@@ -54,6 +167,15 @@ func (v *StringValue) String() string {
 	return fmt.Sprintf("StringValue { Value: %q }", v.Value)
 }
 
+// iterates one character (rune) at a time
+func (v *StringValue) Iterate() []RuntimeValue {
+	chars := make([]RuntimeValue, 0, len(v.Value))
+	for _, r := range v.Value {
+		chars = append(chars, &StringValue{Value: string(r)})
+	}
+	return chars
+}
+
 // boolean
 type BooleanValue struct {
 	Value bool
@@ -72,6 +194,21 @@ func (v *NilValue) String() string {
 	return "NilValue { Value: nil }"
 }
 
+// array, shared by reference like most scripting languages
+type ArrayValue struct {
+	Elements []RuntimeValue
+}
+
+func (v *ArrayValue) Type() ValueType { return ArrayValueType }
+func (v *ArrayValue) String() string {
+	return fmt.Sprintf("ArrayValue { Elements: %v }", v.Elements)
+}
+
+// iterates over a snapshot, so the loop body may push / pop safely
+func (v *ArrayValue) Iterate() []RuntimeValue {
+	return append([]RuntimeValue(nil), v.Elements...)
+}
+
 // reutrn value
 type ReturnValue struct {
 	Value RuntimeValue
@@ -82,13 +219,39 @@ func (r *ReturnValue) String() string {
 	return fmt.Sprintf("ReturnValue { %s }", r.Value.String())
 }
 
+// break / continue signals, unwound by the nearest enclosing loop
+type BreakValue struct{}
+
+func (b *BreakValue) Type() ValueType { return BreakValueType }
+func (b *BreakValue) String() string  { return "BreakValue {}" }
+
+type ContinueValue struct{}
+
+func (c *ContinueValue) Type() ValueType { return ContinueValueType }
+func (c *ContinueValue) String() string  { return "ContinueValue {}" }
+
 // function
 type FunctionValue struct {
+	Name      string
 	Arguments []string
-	Body       *ast.BodyNode
+	Body      *ast.BodyNode
+	Scope     *Scope // scope the literal was evaluated in, calls chain from here
 }
 
 func (f *FunctionValue) Type() ValueType { return FunctionValueType }
 func (f *FunctionValue) String() string {
 	return fmt.Sprintf("FunctionValue { Arguments: %v }", f.Arguments)
-}
\ No newline at end of file
+}
+
+// function implemented in Go: the global builtins as well as built-in
+// methods, which close over their receiver (e.g. the value of "abc".upper)
+type NativeFunctionValue struct {
+	Name  string
+	Arity int // -1 accepts any number of arguments
+	Fn    func(args []RuntimeValue) RuntimeValue
+}
+
+func (f *NativeFunctionValue) Type() ValueType { return NativeFunctionValueType }
+func (f *NativeFunctionValue) String() string {
+	return fmt.Sprintf("NativeFunctionValue { Name: %s }", f.Name)
+}